@@ -29,13 +29,13 @@ func main() {
 }
 
 func index(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
 	data := map[string]any{
 		"Title": "Page Title",
 	}
 
-	err := views.ExecuteTemplate(w, "index", "main", data)
+	// On Reload mode, Render responds with
+	// the development error page on failures.
+	err := views.Render(w, r, "index", "main", data)
 	if err != nil {
 		println(err.Error())
 	}
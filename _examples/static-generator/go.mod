@@ -1,6 +1,6 @@
 module static-generator
 
-go 1.23
+go 1.25
 
 require (
 	github.com/kataras/blocks v0.0.11
@@ -1,11 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/kataras/blocks"
@@ -42,36 +42,35 @@ func main() {
 		log.Fatalf("views: %v", err)
 	}
 
-	// You can get information about templates and their layouts through:
-	// for name := range views.Templates {
-	// 	layouts := make([]string, 0, len(views.Layouts))
-	// 	for layout := range views.Layouts {
-	// 		layouts = append(layouts, layout)
-	// 	}
-	// 	fmt.Printf("[%s] with Layouts [%s]\n", name, strings.Join(layouts, ", "))
-	// }
-
-	err := os.MkdirAll(outputDir, 0777)
-	if err != nil {
-		log.Fatalf("mkdir: %v", err)
+	site := blocks.NewSite(views)
+	site.OutputDir = outputDir
+	site.NotFound = blocks.Route{
+		Template: "404",
+		Layout:   "error",
+		Data: func(context.Context) (any, error) {
+			return data{"Code": http.StatusNotFound, "Message": "The page you are looking for does not exist."}, nil
+		},
 	}
-
-	indexPath := filepath.Join(outputDir, "index.html")
-	indexFile, err := os.OpenFile(indexPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
-	if err != nil {
-		log.Fatalf("open index file: %v", err)
+	site.Route(blocks.Route{
+		Path:     "/",
+		Template: "index",
+		Layout:   "main",
+		Data: func(context.Context) (any, error) {
+			return siteData, nil
+		},
+	})
+
+	report, err := site.Build(context.Background())
+	if report != nil {
+		report.WriteText(os.Stdout)
 	}
-
-	err = views.ExecuteTemplate(indexFile, "index", "main", siteData)
 	if err != nil {
-		log.Fatalf("template exec: %v", err)
+		log.Fatalf("build: %v", err)
 	}
 
-	log.Printf("Generated: %s\n", indexPath)
-
 	// let's serve our static content:
 	log.Println("Now listening on: http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", http.FileServer(http.Dir("./public"))))
+	log.Fatal(http.ListenAndServe(":8080", http.FileServer(http.Dir(outputDir))))
 }
 
 func readConfig(dest map[string]any) error {
@@ -0,0 +1,387 @@
+package blocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"text/template/parse"
+)
+
+// View is a typed renderer of a content template and a layout, see `Bind`.
+type View[T any] struct {
+	v                    *Blocks
+	tmplName, layoutName string
+}
+
+// binding is a template and layout pair bound to a data type, see `Bind`.
+type binding struct {
+	tmplName, layoutName string
+	typ                  reflect.Type
+}
+
+// Bind returns a typed renderer of the "tmplName" content template
+// with the "layoutName" layout (it can be empty, see `ExecuteTemplateContext`)
+// for data of type T.
+//
+// Every field chain the template, its layout and their partials use against
+// the dot or the root data, e.g. {{ .User.Name }} or {{ $.Title }}, is checked
+// to be a field, a method or a map key of T, so renaming a struct field
+// fails on load instead of on render, e.g.
+//
+//	template: index.html:2:11: can't evaluate field Nme in type main.User (did you mean 'Name'?)
+//
+// The fields under a {{ with }} or a {{ range }} block are checked against the block's type.
+// The fields of an interface type value (e.g. `any`) can not be known and are not checked.
+// A mismatch is reported as a `*FieldError`, more than one are joined.
+//
+// If the engine is already loaded the binding is checked immediately,
+// and it is checked again on each `Load`.
+func Bind[T any](v *Blocks, tmplName, layoutName string) (*View[T], error) {
+	b := binding{
+		tmplName:   strings.TrimSuffix(tmplName, v.extension),
+		layoutName: layoutName,
+		typ:        reflect.TypeFor[T](),
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.files != nil {
+		if err := v.checkBinding(b); err != nil {
+			return nil, err
+		}
+	}
+	v.bindings = append(v.bindings, b)
+
+	return &View[T]{v: v, tmplName: tmplName, layoutName: layoutName}, nil
+}
+
+// Execute renders the view with the "data" to "w", see `Blocks.ExecuteTemplate`.
+func (t *View[T]) Execute(w io.Writer, data T) error {
+	return t.v.ExecuteTemplate(w, t.tmplName, t.layoutName, data)
+}
+
+// ExecuteContext renders the view with the "data" to "w", see `Blocks.ExecuteTemplateContext`.
+func (t *View[T]) ExecuteContext(ctx context.Context, w io.Writer, data T) error {
+	return t.v.ExecuteTemplateContext(ctx, w, t.tmplName, t.layoutName, data)
+}
+
+// String renders the view with the "data" and returns its contents, see `Blocks.TemplateString`.
+func (t *View[T]) String(data T) (string, error) {
+	return t.v.TemplateString(t.tmplName, t.layoutName, data)
+}
+
+// Render renders the view with the "data" to the response, see `Blocks.Render`.
+func (t *View[T]) Render(w http.ResponseWriter, r *http.Request, data T) error {
+	return t.v.Render(w, r, t.tmplName, t.layoutName, data)
+}
+
+// FieldError reports a field chain of a template which can not be resolved on its data type,
+// see `Bind`.
+type FieldError struct {
+	// Location is the "file:line:col" of the field.
+	Location string
+	// Field is the field name which was not found on the Type.
+	Field string
+	Type  reflect.Type
+	// Suggestions are the names of the fields and methods of the Type closest to the Field.
+	Suggestions []string
+}
+
+// Error implements the `error` interface.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("template: %s: can't evaluate field %s in type %s%s", e.Location, e.Field, e.Type, didYouMean(e.Suggestions))
+}
+
+// checkBinding checks the content template, the layout and their partials of the "b" binding
+// against its type. It must be called after the templates are loaded.
+func (v *Blocks) checkBinding(b binding) error {
+	tmplName, layoutName := v.resolveLoadedNames(context.Background(), b.tmplName, b.layoutName)
+
+	var contentFile, layoutFile *templateFile
+	for _, file := range v.files {
+		switch {
+		case !file.layout && file.name == tmplName:
+			contentFile = file
+		case file.layout && file.name == layoutName:
+			layoutFile = file
+		}
+	}
+
+	if contentFile == nil {
+		return &TemplateNotExistError{Name: tmplName, Key: tmplName, Suggestions: suggest(tmplName, templateFileNames(v.files, false))}
+	}
+
+	c := &fieldChecker{files: v.files, trees: make(map[*templateFile]map[string]*parse.Tree), visited: make(map[string]struct{})}
+	if layoutName == "" {
+		c.checkFile(contentFile, b.typ)
+		return errors.Join(c.errs...)
+	}
+
+	if layoutFile == nil {
+		key := makeLayoutTemplateName(tmplName, layoutName)
+		return &LayoutNotExistError{Name: layoutName, Template: tmplName, Key: key, Suggestions: suggest(layoutName, templateFileNames(v.files, true))}
+	}
+
+	// The content template is checked against the type(s) the layout renders it with.
+	c.checkFile(layoutFile, b.typ)
+	for _, dot := range c.contentDots {
+		c.checkFile(contentFile, dot)
+	}
+
+	return errors.Join(c.errs...)
+}
+
+// fieldChecker walks the parse trees of the template files
+// and checks their field chains against the type of the dot.
+// A nil type is an unknown one, e.g. an interface value, and it is not checked.
+type fieldChecker struct {
+	files   []*templateFile
+	trees   map[*templateFile]map[string]*parse.Tree
+	visited map[string]struct{} // file, tree and dot type.
+	errs    []error
+	// contentDots are the types a layout renders its content template with.
+	contentDots []reflect.Type
+}
+
+// fieldScope is the file and the root data type of a tree walk.
+type fieldScope struct {
+	file *templateFile
+	root reflect.Type
+}
+
+func (c *fieldChecker) checkFile(file *templateFile, dot reflect.Type) {
+	s := fieldScope{file: file, root: dot}
+	c.tree(s, file.filename, dot)
+	if !file.layout {
+		c.tree(s, "content", dot)
+	}
+}
+
+func (c *fieldChecker) tree(s fieldScope, name string, dot reflect.Type) {
+	trees, ok := c.trees[s.file]
+	if !ok {
+		var err error
+		if trees, err = s.file.parseTrees(); err != nil {
+			c.errs = append(c.errs, mapError(err, c.files))
+		}
+		c.trees[s.file] = trees
+	}
+
+	if s.file.layout && name == "content" {
+		c.contentDots = append(c.contentDots, dot)
+		return
+	}
+
+	tree, ok := trees[name]
+	if !ok {
+		return
+	}
+
+	key := fmt.Sprintf("%s\x00%s\x00%v", s.file.filename, name, dot)
+	if _, ok = c.visited[key]; ok {
+		return
+	}
+	c.visited[key] = struct{}{}
+
+	c.list(s, tree.Root, dot)
+}
+
+func (c *fieldChecker) list(s fieldScope, list *parse.ListNode, dot reflect.Type) {
+	if list == nil {
+		return
+	}
+
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.ActionNode:
+			c.pipe(s, n.Pipe, dot)
+		case *parse.IfNode:
+			c.pipe(s, n.Pipe, dot)
+			c.list(s, n.List, dot)
+			c.list(s, n.ElseList, dot)
+		case *parse.WithNode:
+			c.list(s, n.List, c.pipe(s, n.Pipe, dot))
+			c.list(s, n.ElseList, dot)
+		case *parse.RangeNode:
+			c.list(s, n.List, elemType(c.pipe(s, n.Pipe, dot)))
+			c.list(s, n.ElseList, dot)
+		case *parse.TemplateNode:
+			c.tree(s, n.Name, c.pipe(s, n.Pipe, dot))
+		}
+	}
+}
+
+// pipe checks the "pipe" and returns its result type.
+func (c *fieldChecker) pipe(s fieldScope, pipe *parse.PipeNode, dot reflect.Type) reflect.Type {
+	if pipe == nil {
+		return nil
+	}
+
+	var typ reflect.Type
+	for _, cmd := range pipe.Cmds {
+		typ = c.command(s, cmd, dot)
+	}
+
+	return typ
+}
+
+// command checks the arguments of the "cmd" and returns its result type.
+func (c *fieldChecker) command(s fieldScope, cmd *parse.CommandNode, dot reflect.Type) reflect.Type {
+	types := make([]reflect.Type, len(cmd.Args))
+	for i, arg := range cmd.Args {
+		types[i] = c.operand(s, arg, dot)
+	}
+
+	ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok {
+		return types[0]
+	}
+
+	// {{ partial "name" .Data }}
+	if ident.Ident == "partial" && len(cmd.Args) == 3 {
+		if name, ok := cmd.Args[1].(*parse.StringNode); ok {
+			for _, file := range c.files {
+				if !file.layout && file.name == name.Text {
+					c.checkFile(file, types[2])
+				}
+			}
+		}
+	}
+
+	return nil // the result of a function.
+}
+
+// operand checks the "node" and returns its type.
+func (c *fieldChecker) operand(s fieldScope, node parse.Node, dot reflect.Type) reflect.Type {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return c.fields(s, n, dot, n.Ident)
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" {
+			return c.fields(s, n, s.root, n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		return c.fields(s, n, c.operand(s, n.Node, dot), n.Field)
+	case *parse.PipeNode:
+		return c.pipe(s, n, dot)
+	}
+
+	return nil
+}
+
+// fields resolves the "names" field chain on the "typ" and returns its type.
+func (c *fieldChecker) fields(s fieldScope, node parse.Node, typ reflect.Type, names []string) reflect.Type {
+	for _, name := range names {
+		if typ == nil {
+			return nil
+		}
+
+		next, ok := fieldType(typ, name)
+		if !ok {
+			line, col := s.file.position(int(node.Position()))
+			c.errs = append(c.errs, &FieldError{
+				Location:    fmt.Sprintf("%s:%d:%d", s.file.filename, line, col),
+				Field:       name,
+				Type:        typ,
+				Suggestions: suggest(name, fieldNames(typ)),
+			})
+			return nil
+		}
+
+		typ = next
+	}
+
+	return typ
+}
+
+// fieldType returns the type of the "name" field, method (first result) or map key of the "typ",
+// as text/template resolves them. A nil type is returned for the unknown ones.
+func fieldType(typ reflect.Type, name string) (reflect.Type, bool) {
+	if typ.Kind() == reflect.Interface {
+		return nil, true
+	}
+
+	method, ok := typ.MethodByName(name)
+	if !ok && typ.Kind() != reflect.Pointer {
+		method, ok = reflect.PointerTo(typ).MethodByName(name)
+	}
+	if ok {
+		if method.Type.NumOut() == 0 {
+			return nil, true
+		}
+		return method.Type.Out(0), true
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if field, ok := typ.FieldByName(name); ok && field.IsExported() {
+			return field.Type, true
+		}
+	case reflect.Map:
+		if reflect.TypeFor[string]().AssignableTo(typ.Key()) {
+			return typ.Elem(), true
+		}
+	case reflect.Interface:
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// fieldNames returns the exported field and method names of the "typ".
+func fieldNames(typ reflect.Type) []string {
+	methods := typ
+	if typ.Kind() != reflect.Pointer {
+		methods = reflect.PointerTo(typ)
+	}
+
+	var names []string
+	for i := 0; i < methods.NumMethod(); i++ {
+		names = append(names, methods.Method(i).Name)
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() == reflect.Struct {
+		for _, field := range reflect.VisibleFields(typ) {
+			if field.IsExported() {
+				names = append(names, field.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// elemType returns the type of the elements a {{ range }} over a "typ" value iterates.
+func elemType(typ reflect.Type) reflect.Type {
+	if typ == nil {
+		return nil
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return typ.Elem()
+	case reflect.Int:
+		return typ
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package blocks_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+type bindUser struct {
+	Name  string
+	Posts []bindPost
+	Meta  map[string]any
+}
+
+func (u bindUser) Initial() string { return u.Name[:1] }
+
+type bindPost struct {
+	Title string
+}
+
+type bindPage struct {
+	Title string
+	User  *bindUser
+}
+
+func TestBind(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<title>{{ .Title }}</title>{{ yield . }}`), nil)
+	mfs.ParseTemplate("index.html", []byte(`{{ with .User }}<h1>{{ .Name }} ({{ .Initial }})</h1>
+{{ range .Posts }}<p>{{ .Title }} by {{ $.User.Name }}</p>{{ end }}{{ .Meta.anything.goes }}{{ end }}
+{{ partial "user" .User }}`), nil)
+	mfs.ParseTemplate("user.html", []byte(`<b>{{ .Name }}</b>`), nil)
+
+	views := blocks.New(mfs)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := blocks.Bind[bindPage](views, "index", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := view.String(bindPage{Title: "Home", User: &bindUser{Name: "kataras", Posts: []bindPost{{Title: "Go"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "<title>Home</title><h1>kataras (k)</h1>\n<p>Go by kataras</p>\n<b>kataras</b>"; got != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	// Mismatches of the template, the layout and the partial.
+	_, err = blocks.Bind[bindUser](views, "index", "main")
+	expected := []string{
+		`template: layouts/main.html:1:10: can't evaluate field Title in type blocks_test.bindUser`,
+		`template: index.html:1:8: can't evaluate field User in type blocks_test.bindUser`,
+		`template: index.html:2:41: can't evaluate field User in type blocks_test.bindUser`,
+		`template: index.html:3:18: can't evaluate field User in type blocks_test.bindUser`,
+	}
+	if err == nil || err.Error() != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\nbut got:\n%v", strings.Join(expected, "\n"), err)
+	}
+
+	// A renamed field fails on load.
+	mfs.ParseTemplate("user.html", []byte(`<b>{{ .Nme }}</b>`), nil)
+	err = views.Load()
+	var fieldErr *blocks.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Error() != `template: user.html:1:6: can't evaluate field Nme in type *blocks_test.bindUser (did you mean 'Name'?)` {
+		t.Fatalf("expected a field error but got %T: %v", err, err)
+	}
+
+	mfs.ParseTemplate("user.html", []byte(`<b>{{ .Name }}</b>`), nil)
+	if err = views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = blocks.Bind[bindPage](views, "indx", ""); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a not exist error but got: %v", err)
+	}
+}
@@ -9,9 +9,12 @@ import (
 	"net/http"
 	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/russross/blackfriday/v2"
 	"github.com/valyala/bytebufferpool"
@@ -21,6 +24,9 @@ import (
 type ExtensionParser func([]byte) ([]byte, error)
 
 // ErrNotExist reports whether a template was not found in the parsed templates tree.
+// The engine returns the more specific `TemplateNotExistError`, `LayoutNotExistError`
+// and `LayoutTemplateNotExistError`, which all unwrap to it,
+// so it should be checked through errors.As (or errors.Is for a specific name), not ==.
 type ErrNotExist struct {
 	Name string
 }
@@ -51,10 +57,49 @@ type Blocks struct {
 	// The default contains an entry of ".md" for `blackfriday.Run`.
 	extensionHandler map[string]ExtensionParser // key = extension with dot, value = parser.
 
+	// contextFuncs are resolved per execution, see `ContextFuncs`.
+	contextFuncs map[string]reflect.Value
+	// sharedData are merged into the data of every execution, see `SharedData`.
+	sharedData map[string]any
+	// observers are notified about loads and renders, see `Observe`.
+	observers []Observer
+	// coverage is not nil on the coverage instrumentation mode, see `Coverage`.
+	coverage *coverage
+	// missingFunc is registered under the name of each unknown function, see `MissingFuncs`.
+	missingFunc any
+	// injectors of the pages rendered with a layout, e.g. see `NewLiveReload`.
+	injectors []injector
+	// toolbar is the development toolbar, see `DevToolbar`.
+	toolbar *toolbar
+	// sandbox is not nil on the sandbox mode, see `Sandbox`.
+	sandbox *Sandbox
+	// fileDelims are the delimiters of specific files, see `DelimsFor`.
+	fileDelims []fileDelims
+	// options are the template options, see `Option`.
+	options []string
+	// strict mode, see `Strict`.
+	strict bool
+	// bindings are checked on each load, see `Bind`.
+	bindings []binding
+	// collectionDirs are the registered content collections, see `Collection`.
+	collectionDirs []collectionDir
+	// searchPartial registers the built-in "search" template, see `SearchPartial`.
+	searchPartial bool
+
 	// parse the templates on each request.
 	reload     bool
 	mu         sync.RWMutex
 	bufferPool *bytebufferpool.Pool
+	executions sync.Map // *template.Template (loaded) -> *sync.Pool of *execution, see `ContextFuncs`.
+
+	// frontMatter of the content templates, see `FrontMatter`.
+	frontMatter map[string]map[string]any
+	// files are the last loaded template files.
+	files []*templateFile
+	// collections are the loaded content collections, see `Entries`.
+	collections map[string]Collection
+	// collectionsDigest is the digest of the collections files, see `Site.Incremental`.
+	collectionsDigest string
 
 	// Root, Templates and Layouts can be accessed after `Load`.
 	Root               *template.Template
@@ -97,10 +142,12 @@ func New(fs any) *Blocks {
 		// Note that, this is parsed, the delims can be configured later on.
 		Root: template.Must(template.New("root").
 			Parse(`{{ define "root" }} {{- template "content" . -}} {{ end }}`)),
-		Templates:  make(map[string]*template.Template),
-		Layouts:    make(map[string]*template.Template),
-		reload:     false,
-		bufferPool: new(bytebufferpool.Pool),
+		Templates:   make(map[string]*template.Template),
+		Layouts:     make(map[string]*template.Template),
+		frontMatter: make(map[string]map[string]any),
+		collections: make(map[string]Collection),
+		reload:      false,
+		bufferPool:  new(bytebufferpool.Pool),
 	}
 
 	v.Root.Funcs(translateFuncs(v, builtins))
@@ -135,9 +182,11 @@ var (
 // definitions will inherit the settings. An empty delimiter stands for the
 // corresponding default: {{ or }}.
 // The return value is the engine, so calls can be chained.
+//
+// The delimiters apply to all templates, content templates, layouts and partials,
+// except the ones with their own delimiters, see `DelimsFor`.
 func (v *Blocks) Delims(left, right string) *Blocks {
-	v.left = left
-	v.right = right
+	v.left, v.right = defaultDelims(left, right)
 	v.Root.Delims(left, right)
 	return v
 }
@@ -170,11 +219,39 @@ func (v *Blocks) Delims(left, right string) *Blocks {
 //		The operation returns the zero value for the map type's element.
 //	"missingkey=error"
 //		Execution stops immediately with an error.
+//
+// The options apply to all templates, content templates, layouts and partials.
 func (v *Blocks) Option(opt ...string) *Blocks {
 	v.Root.Option(opt...)
+	v.options = append(v.options, opt...)
+	return v
+}
+
+// Strict turns on the strict mode. On strict mode the templates fail,
+// with the position of the failing action, instead of printing
+// "<no value>" or an empty value when:
+//   - a field is missing, including a map key, e.g. a missing ".Title" of a map data,
+//   - a key is looked up on a nil map or a nil data,
+//   - a partial of a literal name, e.g. {{ partial "footer" . }}, does not exist; this is reported on `Load`.
+//
+// It applies the "missingkey=error" option to all templates, see `Option`.
+// It should be called before the engine is loaded.
+func (v *Blocks) Strict(b bool) *Blocks {
+	v.strict = b
 	return v
 }
 
+// templateOptions returns the options of the templates, see `Option` and `Strict`.
+func (v *Blocks) templateOptions() []string {
+	options := make([]string, 0, len(v.options)+1)
+	options = append(options, v.options...)
+	if v.strict {
+		options = append(options, "missingkey=error")
+	}
+
+	return options
+}
+
 // Funcs adds the elements of the argument map to the root template's function map.
 // It must be called before the engine is loaded.
 // It panics if a value in the map is not a function with appropriate return
@@ -298,101 +375,253 @@ func (v *Blocks) LoadWithContext(ctx context.Context) error {
 
 	clearMap(v.Templates)
 	clearMap(v.Layouts)
+	clearMap(v.frontMatter)
+	clearMap(v.collections)
+	v.executions.Clear()
+	v.files = nil
 
-	return v.load(ctx)
+	if len(v.observers) == 0 {
+		return v.load(ctx)
+	}
+
+	start := time.Now()
+	v.notifyLoadStart(start)
+	err := v.load(ctx)
+
+	filenames := make([]string, 0, len(v.files))
+	for _, file := range v.files {
+		filenames = append(filenames, file.filename)
+	}
+	sort.Strings(filenames)
+
+	v.notifyLoadFinish(start, filenames, err)
+	return err
 }
 
-func (v *Blocks) load(ctx context.Context) error {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+// templateFile holds the contents of a template file
+// after the engine's transformations, ready to be parsed.
+type templateFile struct {
+	filename string // the path of the file, relative to the file system's root.
+	name     string // the template (or layout) name, e.g. "index" or "main".
+	contents string
+	layout   bool
+	// frontMatter holds the parsed front matter, if any, see `FrontMatter`.
+	frontMatter map[string]any
+	// left and right are the action delimiters of the file, see `DelimsFor`.
+	left, right string
+	// source is the original contents of the file
+	// and smap maps the positions of its contents back to it.
+	source string
+	smap   *sourceMap
+}
 
+// readTemplateFiles reads all template files from the engine's file system
+// and transforms their contents exactly as `Load` does before parsing them.
+// Each transformation is recorded to the file's source map.
+func (v *Blocks) readTemplateFiles(ctx context.Context) ([]*templateFile, error) {
 	filesMap, err := readFiles(ctx, v.fs, v.rootDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(filesMap) == 0 {
-		return fmt.Errorf("no template files found")
+		return nil, fmt.Errorf("no template files found")
 	}
 
-	// templatesContents is used to keep the contents of each content template in order
-	// to be parsed on each layout, so all content templates have all layouts available,
-	// and all layouts can inject all content templates.
-	contentTemplates := make(map[string]string)
-	// layoutTemplates is used to keep the contents of each layout template.
-	layoutTemplates := make(map[string]string)
+	if v.searchPartial {
+		if err = v.addSearchPartial(filesMap); err != nil {
+			return nil, err
+		}
+	}
 
-	// collect all content and layout template contents.
+	files := make([]*templateFile, 0, len(filesMap))
 	for filename, data := range filesMap {
+		if v.inCollection(filename) {
+			continue // loaded as data, see `Collection`.
+		}
+
 		ext := path.Ext(filename)
-		if extParser := v.extensionHandler[ext]; extParser != nil {
-			data, err = extParser(data) // let the parser modify the contents.
+		extParser := v.extensionHandler[ext]
+		if extParser == nil && ext != v.extension {
+			continue // extension not match with the given template extension and the extension handler is nil.
+		}
+
+		file := &templateFile{filename: filename, source: string(data)}
+		file.smap = newSourceMap(file.source)
+		file.contents = file.source
+
+		// Trim top and bottom space.
+		file.edit(trimSpaceEdits(file.contents)...)
+
+		// Extract the front matter before any extension parser sees the contents.
+		frontMatter, body, err := splitFrontMatter([]byte(file.contents))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		file.frontMatter = frontMatter
+		if n := len(file.contents) - len(body); n > 0 {
+			file.edit(sourceEdit{start: 0, end: n})
+		}
+
+		file.left, file.right, err = v.delimsOf(filename, frontMatter)
+		if err != nil {
+			return nil, err
+		}
+
+		if extParser != nil {
+			data, err = extParser([]byte(file.contents)) // let the parser modify the contents.
 			if err != nil {
 				// custom parsers may return a non-nil error,
 				// e.g. less or scss files
 				// and, yes, they can be used as templates too,
 				// because they are wrapped by a template block if necessary.
-				return err
+				return nil, err
 			}
-		} else if ext != v.extension {
-			continue // extension not match with the given template extension and the extension handler is nil.
+
+			file.smap.anchor(file.contents, string(data), file.left, file.right)
+			file.contents = string(data)
 		}
 
-		contents := string(data)
 		// Remove HTML comments.
-		contents = removeComments(contents)
+		file.edit(regexpEdits(matchHTMLCommentsRegex, file.contents, "")...)
 
 		tmplName := trimDir(filename, v.rootDir)
 		tmplName = strings.TrimPrefix(tmplName, "/")
 		tmplName = strings.TrimSuffix(tmplName, v.extension)
 
-		if isLayoutTemplate(contents) {
+		re := regexpsOf(file.left, file.right)
+		if re.layout.MatchString(file.contents) {
 			// Replace any {{ yield . }} with {{ template "content" . }}.
-			contents = replaceYieldWithTemplateContent(contents)
+			file.edit(regexpEdits(re.yield, file.contents, re.yieldReplacement)...)
 			// Remove any given layout dir.
 			tmplName = trimDir(tmplName, v.layoutDir)
-			layoutTemplates[tmplName] = contents
+			file.layout = true
+		} else if !strings.Contains(file.contents, defineStart(file.left)) && !strings.Contains(file.contents, defineStartNoSpace(file.left)) {
+			// Inject the define content block.
+			file.edit(
+				sourceEdit{start: 0, end: 0, text: defineContentStart(file.left, file.right)},
+				sourceEdit{start: len(file.contents), end: len(file.contents), text: defineContentEnd(file.left, file.right)},
+			)
+		}
+
+		file.name = tmplName
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+func (v *Blocks) load(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	files, err := v.readTemplateFiles(ctx)
+	if err != nil {
+		return err
+	}
+	v.files = files
+
+	if err = v.loadCollections(ctx); err != nil {
+		return err
+	}
+
+	if v.sandbox != nil {
+		if err = v.sandbox.checkFuncs(files); err != nil {
+			return err
+		}
+	}
+
+	if v.strict {
+		if err = v.checkPartials(files); err != nil {
+			return err
+		}
+	}
+
+	if v.coverage != nil {
+		v.coverage.reset()
+		for _, file := range files {
+			if err = v.coverage.instrument(file); err != nil {
+				return err
+			}
+		}
+	}
+
+	// templatesContents is used to keep the contents of each content template in order
+	// to be parsed on each layout, so all content templates have all layouts available,
+	// and all layouts can inject all content templates.
+	contentTemplates := make(map[string]*templateFile)
+	// layoutTemplates is used to keep the contents of each layout template.
+	layoutTemplates := make(map[string]*templateFile)
+
+	// collect all content and layout template contents.
+	for _, file := range files {
+		if file.layout {
+			layoutTemplates[file.name] = file
 			continue
 		}
 
-		// Inject the define content block.
-		if !strings.Contains(contents, defineStart(v.left)) && !strings.Contains(contents, defineStartNoSpace(v.left)) {
-			contents = defineContentStart(v.left, v.right) + contents + defineContentEnd(v.left, v.right)
+		contentTemplates[file.name] = file
+		if file.frontMatter != nil {
+			v.frontMatter[file.name] = file.frontMatter
 		}
+	}
 
-		contentTemplates[tmplName] = contents
+	// loadFuncs are registered to all templates, content and layouts.
+	// The context funcs are bound to the context of each execution,
+	// these are registered so the templates can be parsed.
+	loadFuncs := v.bindContextFuncs(context.Background)
+	if v.coverage != nil {
+		loadFuncs[coverFuncName] = v.coverage.cover
 	}
+	if v.missingFunc != nil {
+		if err = v.stubMissingFuncs(files, loadFuncs); err != nil {
+			return err
+		}
+	}
+
+	options := v.templateOptions()
 
 	// Load the content templates first.
-	for tmplName, contents := range contentTemplates {
+	// They are parsed under their file name, so parse and execution errors report the file.
+	for tmplName, file := range contentTemplates {
 		tmpl, err := v.Root.Clone()
 		if err != nil {
 			return err
 		}
+		tmpl.Option(options...)
 
-		_, err = tmpl.Funcs(v.tmplFuncs).Parse(contents)
+		_, err = tmpl.Funcs(v.tmplFuncs).Funcs(loadFuncs).New(file.filename).Delims(file.left, file.right).Parse(file.contents)
 		if err != nil {
-			return fmt.Errorf("%w: %s: %s", err, tmplName, contents)
+			return fmt.Errorf("%w: %s", mapError(err, files), tmplName)
 		}
 
 		v.Templates[tmplName] = tmpl
 	}
 
 	// Load the layout templates.
+	// They share the delimiters, options and funcs of the content templates,
+	// the layout funcs override the content templates ones.
 	layoutBuiltinFuncs := translateFuncs(v, builtins)
-	for tmplName, contents := range layoutTemplates {
-		for contentTmplName, contentTmplContents := range contentTemplates {
+	for tmplName, layoutFile := range layoutTemplates {
+		for contentTmplName, contentFile := range contentTemplates {
 			// Make new layout template for each of the content templates,
 			// the key of the layout in map will be the layoutName+tmplName.
 			// So each template owns all layouts. This fixes the issue with the new {{ block }} and the usual {{ define }} directives.
-			layoutTmpl, err := template.New(tmplName).Funcs(layoutBuiltinFuncs).Funcs(v.layoutFuncs).Parse(contents)
+			layoutTmpl, err := template.New(tmplName).
+				Delims(layoutFile.left, layoutFile.right).
+				Option(options...).
+				Funcs(layoutBuiltinFuncs).
+				Funcs(v.tmplFuncs).
+				Funcs(v.layoutFuncs).
+				Funcs(loadFuncs).
+				Parse(layoutFile.contents)
 			if err != nil {
-				return fmt.Errorf("%w: for layout: %s", err, tmplName)
+				return fmt.Errorf("%w: for layout: %s", mapError(err, files), tmplName)
 			}
 
-			_, err = layoutTmpl.Funcs(v.tmplFuncs).Parse(contentTmplContents)
+			_, err = layoutTmpl.New(contentFile.filename).Delims(contentFile.left, contentFile.right).Parse(contentFile.contents)
 			if err != nil {
-				return fmt.Errorf("%w: layout: %s: for template: %s", err, tmplName, contentTmplName)
+				return fmt.Errorf("%w: layout: %s: for template: %s", mapError(err, files), tmplName, contentTmplName)
 			}
 
 			key := makeLayoutTemplateName(contentTmplName, tmplName)
@@ -400,9 +629,46 @@ func (v *Blocks) load(ctx context.Context) error {
 		}
 	}
 
+	for _, b := range v.bindings {
+		if err = v.checkBinding(b); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// TemplateNames returns the sorted names of the loaded content templates,
+// e.g. "index" and "partials/footer".
+func (v *Blocks) TemplateNames() []string {
+	return v.fileNames(false)
+}
+
+// LayoutNames returns the sorted names of the loaded layouts, e.g. "main".
+func (v *Blocks) LayoutNames() []string {
+	return v.fileNames(true)
+}
+
+func (v *Blocks) fileNames(layouts bool) []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return templateFileNames(v.files, layouts)
+}
+
+// templateFileNames returns the sorted names of the content templates or the layouts of the "files".
+func templateFileNames(files []*templateFile, layouts bool) []string {
+	var names []string
+	for _, file := range files {
+		if file.layout == layouts {
+			names = append(names, file.name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
 // ExecuteTemplate applies the template associated with "tmplName"
 // to the specified "data" object and writes the output to "w".
 // If an error occurs executing the template or writing its output,
@@ -414,21 +680,69 @@ func (v *Blocks) load(ctx context.Context) error {
 //
 // A template may be executed safely in parallel, although if parallel
 // executions share a Writer the output may be interleaved.
+//
+// See `ExecuteTemplateContext` too.
 func (v *Blocks) ExecuteTemplate(w io.Writer, tmplName, layoutName string, data any) error {
+	return v.ExecuteTemplateContext(context.Background(), w, tmplName, layoutName, data)
+}
+
+// ExecuteTemplateContext same as `ExecuteTemplate` but it accepts a context,
+// e.g. the http.Request.Context(). The functions registered through `ContextFuncs`
+// are resolved against this "ctx". The rendering stops and the context's error
+// is returned when the "ctx" is canceled or its deadline is exceeded.
+//
+// When "layoutName" is empty, the layout is resolved with the following precedence:
+// the context's layout (see `SetLayout`), the template's "layout" front matter
+// (see `FrontMatter`) and the `DefaultLayout`.
+//
+// On `Reload` mode the load and render errors are returned as `*TemplateError`,
+// see `RenderError`.
+func (v *Blocks) ExecuteTemplateContext(ctx context.Context, w io.Writer, tmplName, layoutName string, data any) error {
 	if v.reload {
 		if err := v.Load(); err != nil {
-			return err
+			return v.renderError(ctx, err, tmplName, layoutName, data)
 		}
 	}
 
-	if layoutName == "" {
-		layoutName = v.defaultLayoutName
+	return v.render(ctx, w, tmplName, layoutName, data)
+}
+
+// render is the `ExecuteTemplateContext` without the templates reload.
+func (v *Blocks) render(ctx context.Context, w io.Writer, tmplName, layoutName string, data any) error {
+	tmplName, layoutName = v.resolveNames(ctx, tmplName, layoutName)
+	return v.renderResolved(ctx, w, tmplName, layoutName, data)
+}
+
+// renderResolved is the `render` of the resolved "tmplName" and "layoutName",
+// an empty "layoutName" renders the template without a layout.
+func (v *Blocks) renderResolved(ctx context.Context, w io.Writer, tmplName, layoutName string, data any) error {
+	ctx, cancel := v.sandboxContext(ctx)
+	defer cancel()
+
+	mergedData := v.mergeData(ctx, data)
+
+	err := v.observeRender(ctx, w, tmplName, layoutName, func(ctx context.Context, w io.Writer) error {
+		return v.executePage(ctx, w, tmplName, layoutName, mergedData)
+	})
+	if err != nil {
+		return v.renderError(ctx, err, tmplName, layoutName, data)
 	}
 
-	return v.executeTemplate(w, tmplName, layoutName, data)
+	return nil
+}
+
+// CheckTemplate reports whether the "tmplName" content template
+// with the "layoutName" layout, if not empty, is loaded.
+// It returns nil or one of the not found errors, see `TemplateNotExistError`.
+// The layout is not resolved, see `ExecuteTemplateContext`.
+func (v *Blocks) CheckTemplate(tmplName, layoutName string) error {
+	_, err := v.lookupTemplate(tmplName, layoutName)
+	return err
 }
 
-func (v *Blocks) executeTemplate(w io.Writer, tmplName, layoutName string, data any) error {
+// lookupTemplate returns the loaded template of the "tmplName" content template
+// with the "layoutName" layout, if not empty.
+func (v *Blocks) lookupTemplate(tmplName, layoutName string) (*template.Template, error) {
 	tmplName = strings.TrimSuffix(tmplName, v.extension) // trim any extension provided by mistake or by migrating from other engines.
 
 	if layoutName != "" {
@@ -436,17 +750,30 @@ func (v *Blocks) executeTemplate(w io.Writer, tmplName, layoutName string, data
 		layoutName = strings.TrimPrefix(layoutName, v.layoutDir)
 		layoutName = strings.TrimPrefix(layoutName, "/")
 
+		v.mu.RLock()
 		tmpl := v.getTemplateWithLayout(tmplName, layoutName)
+		v.mu.RUnlock()
 		if tmpl == nil {
-			return ErrNotExist{layoutName}
+			return nil, v.notExistError(tmplName, layoutName)
 		}
 
-		return tmpl.Execute(w, data)
+		return tmpl, nil
 	}
 
+	v.mu.RLock()
 	tmpl, ok := v.Templates[tmplName]
+	v.mu.RUnlock()
 	if !ok {
-		return ErrNotExist{tmplName}
+		return nil, v.notExistError(tmplName, "")
+	}
+
+	return tmpl, nil
+}
+
+func (v *Blocks) executeTemplate(ctx context.Context, w io.Writer, tmplName, layoutName string, data any) error {
+	tmpl, err := v.lookupTemplate(tmplName, layoutName)
+	if err != nil {
+		return err
 	}
 
 	// if httpResponseWriter, ok := w.(http.ResponseWriter); ok {
@@ -454,20 +781,72 @@ func (v *Blocks) executeTemplate(w io.Writer, tmplName, layoutName string, data
 	// 	httpResponseWriter.Header().Set("Content-Type", "text/html; charset=utf-8")
 	// }  ^ No, leave it for the caller.
 
-	// if layoutName != "" {
-	// 	return tmpl.ExecuteTemplate(w, layoutName, data)
-	// }
-	return tmpl.Execute(w, data)
+	if v.sandbox != nil && v.sandbox.MaxOutput > 0 {
+		w = &limitWriter{w: w, limit: v.sandbox.MaxOutput}
+	}
+
+	if ctx.Done() != nil {
+		if ctx.Err() != nil {
+			return contextErr(ctx)
+		}
+
+		w = &contextWriter{ctx: ctx, w: w}
+	}
+
+	err = v.execute(ctx, w, tmpl, data)
+	if err == nil && v.sandbox != nil && ctx.Err() != nil {
+		return contextErr(ctx) // the timeout of a render which wrote nothing after it.
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return contextErr(ctx)
+		}
+
+		v.mu.RLock()
+		err = mapError(err, v.files)
+		v.mu.RUnlock()
+	}
+
+	return err
+}
+
+func (v *Blocks) execute(ctx context.Context, w io.Writer, tmpl *template.Template, data any) error {
+	if !v.needsExecution() {
+		return tmpl.Execute(w, data)
+	}
+
+	e, err := v.acquireExecution(ctx, tmpl)
+	if err != nil {
+		return err
+	}
+	defer v.releaseExecution(tmpl, e)
+
+	return e.tmpl.Execute(w, data)
 }
 
 // TemplateString executes a template based on its "tmplName" name and returns its contents result.
 // Note that, this does not reload the templates on each call if Reload was set to true.
 // To refresh the templates you have to manually call the `Load` upfront.
 func (v *Blocks) TemplateString(tmplName, layoutName string, data any) (string, error) {
+	ctx, cancel := v.sandboxContext(context.Background())
+	defer cancel()
+
+	data = v.mergeData(ctx, data)
+
+	b := v.bufferPool.Get()
+	err := v.observeRender(ctx, b, tmplName, layoutName, func(ctx context.Context, w io.Writer) error {
+		return v.executeTemplate(ctx, w, tmplName, layoutName, data)
+	})
+	contents := b.String()
+	v.bufferPool.Put(b)
+	return contents, err
+}
+
+func (v *Blocks) templateString(ctx context.Context, tmplName, layoutName string, data any) (string, error) {
 	b := v.bufferPool.Get()
 	// use the unexported method so it does not re-reload the templates on each partial one
 	// when Reload was set to true.
-	err := v.executeTemplate(b, tmplName, layoutName, data)
+	err := v.executeTemplate(ctx, b, tmplName, layoutName, data)
 	contents := b.String()
 	v.bufferPool.Put(b)
 	return contents, err
@@ -475,12 +854,30 @@ func (v *Blocks) TemplateString(tmplName, layoutName string, data any) (string,
 
 // PartialFunc returns the parsed result of the "partialName" template's "content" block.
 func (v *Blocks) PartialFunc(partialName string, data any) (template.HTML, error) {
+	return v.partial(context.Background(), partialName, data)
+}
+
+func (v *Blocks) partial(ctx context.Context, partialName string, data any) (template.HTML, error) {
 	// contents, err := v.ParseTemplate(partialName, "content", data)
 	// if err != nil {
 	// 	return "", err
 	// }
-	contents, err := v.TemplateString(partialName, "", data)
+	if v.sandbox != nil {
+		if err := v.sandbox.checkPartial(ctx, partialName); err != nil {
+			return "", err
+		}
+	}
+
+	start := time.Now()
+	contents, err := v.templateString(ctx, partialName, "", data)
+	if err == nil && ctx.Err() != nil {
+		err = contextErr(ctx)
+	}
+	v.notifyPartial(ctx, partialName, start, len(contents), err)
 	if err != nil {
+		if v.reload && ctx.Err() == nil {
+			err = v.templateError(err, v.stackFrame("partial", partialName, data))
+		}
 		return "", err
 	}
 	return template.HTML(contents), nil
@@ -513,6 +910,37 @@ func Set(v *Blocks) func(http.Handler) http.Handler {
 	}
 }
 
+// Render renders the "tmplName" template with the "layoutName" layout
+// using the request's context, see `ExecuteTemplateContext`.
+// It sets the Content-Type header to "text/html; charset=utf-8"
+// if it's missing.
+//
+// On `Reload` mode the page is buffered and, on failure,
+// the development error page is sent instead, see `RenderError`.
+// The error is returned in both cases.
+func (v *Blocks) Render(w http.ResponseWriter, r *http.Request, tmplName, layoutName string, data any) error {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+
+	if !v.reload {
+		return v.ExecuteTemplateContext(r.Context(), w, tmplName, layoutName, data)
+	}
+
+	b := v.bufferPool.Get()
+	defer v.bufferPool.Put(b)
+
+	if err := v.ExecuteTemplateContext(r.Context(), b, tmplName, layoutName, data); err != nil {
+		if r.Context().Err() == nil {
+			v.RenderError(w, r, err)
+		}
+		return err
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
 // Get retrieves the associated Blocks view engine retrieved from the request's context.
 // See `Set` too.
 func Get(r *http.Request) *Blocks {
@@ -529,6 +957,133 @@ func Get(r *http.Request) *Blocks {
 	return v
 }
 
+type (
+	layoutContextKey struct{}
+	localeContextKey struct{}
+	themeContextKey  struct{}
+)
+
+// SetLayout returns a handler wrapper which stores the "layoutName"
+// in the request's context, so a group of routes, e.g. /admin/*,
+// is rendered with that layout when the handler passes an empty layout
+// to `ExecuteTemplateContext` or `Render`.
+// See `WithLayout` and `LayoutFromContext` too.
+func SetLayout(layoutName string) func(http.Handler) http.Handler {
+	return setContextValue(layoutContextKey{}, layoutName)
+}
+
+// WithLayout returns a copy of "ctx" which holds the "layoutName".
+// See `SetLayout` too.
+func WithLayout(ctx context.Context, layoutName string) context.Context {
+	return context.WithValue(ctx, layoutContextKey{}, layoutName)
+}
+
+// LayoutFromContext returns the layout name stored in "ctx" through `SetLayout` or `WithLayout`.
+func LayoutFromContext(ctx context.Context) string {
+	return contextString(ctx, layoutContextKey{})
+}
+
+// SetLocale returns a handler wrapper which stores the "locale"
+// in the request's context. On `ExecuteTemplateContext`, a localized variant of
+// the template, named after the template and the locale (e.g. "index.el" for "index"),
+// is preferred over the template itself, if it exists.
+// See `WithLocale` and `LocaleFromContext` too.
+func SetLocale(locale string) func(http.Handler) http.Handler {
+	return setContextValue(localeContextKey{}, locale)
+}
+
+// WithLocale returns a copy of "ctx" which holds the "locale".
+// See `SetLocale` too.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// LocaleFromContext returns the locale stored in "ctx" through `SetLocale` or `WithLocale`.
+func LocaleFromContext(ctx context.Context) string {
+	return contextString(ctx, localeContextKey{})
+}
+
+// SetTheme returns a handler wrapper which stores the "theme"
+// in the request's context. On `ExecuteTemplateContext`, a themed variant of
+// the layout, located under the theme's directory inside the layouts one
+// (e.g. "dark/main" for "main"), is preferred over the layout itself, if it exists.
+// See `WithTheme` and `ThemeFromContext` too.
+func SetTheme(theme string) func(http.Handler) http.Handler {
+	return setContextValue(themeContextKey{}, theme)
+}
+
+// WithTheme returns a copy of "ctx" which holds the "theme".
+// See `SetTheme` too.
+func WithTheme(ctx context.Context, theme string) context.Context {
+	return context.WithValue(ctx, themeContextKey{}, theme)
+}
+
+// ThemeFromContext returns the theme stored in "ctx" through `SetTheme` or `WithTheme`.
+func ThemeFromContext(ctx context.Context) string {
+	return contextString(ctx, themeContextKey{})
+}
+
+func setContextValue(key any, value string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(context.WithValue(r.Context(), key, value))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func contextString(ctx context.Context, key any) string {
+	if ctx == nil {
+		return ""
+	}
+
+	s, _ := ctx.Value(key).(string)
+	return s
+}
+
+// resolveNames returns the template and layout names to render,
+// based on the "ctx" values, the front matter and the default layout.
+// The layout's precedence, from highest to lowest, is:
+// the given "layoutName", the context's layout, the template's front matter
+// layout and the `DefaultLayout`.
+func (v *Blocks) resolveNames(ctx context.Context, tmplName, layoutName string) (string, string) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.resolveLoadedNames(ctx, tmplName, layoutName)
+}
+
+// resolveLoadedNames is the `resolveNames` for callers which hold the engine's lock already, e.g. `Load`.
+func (v *Blocks) resolveLoadedNames(ctx context.Context, tmplName, layoutName string) (string, string) {
+	tmplName = strings.TrimSuffix(tmplName, v.extension)
+
+	if locale := LocaleFromContext(ctx); locale != "" {
+		if localized := tmplName + "." + locale; v.Templates[localized] != nil {
+			tmplName = localized
+		}
+	}
+
+	if layoutName == "" {
+		layoutName = LayoutFromContext(ctx)
+	}
+
+	if layoutName == "" {
+		layoutName, _ = v.frontMatter[tmplName]["layout"].(string)
+	}
+
+	if layoutName == "" {
+		layoutName = v.defaultLayoutName
+	}
+
+	if theme := ThemeFromContext(ctx); theme != "" && layoutName != "" {
+		if themed := path.Join(theme, layoutName); v.getTemplateWithLayout(tmplName, themed) != nil {
+			layoutName = themed
+		}
+	}
+
+	return tmplName, layoutName
+}
+
 func withSuffix(s string, suf string) string {
 	if len(s) == 0 {
 		return ""
@@ -561,6 +1116,8 @@ func trimDir(s string, dir string) string {
 	return strings.TrimPrefix(s, dir)
 }
 
+// getTemplateWithLayout returns the loaded template of the "tmplName" with the "layoutName" layout.
+// The caller must hold the engine's lock.
 func (v *Blocks) getTemplateWithLayout(tmplName, layoutName string) *template.Template {
 	key := makeLayoutTemplateName(tmplName, layoutName)
 	return v.Layouts[key]
@@ -573,25 +1130,6 @@ func makeLayoutTemplateName(tmplName, layoutName string) string {
 // Regular expression to match HTML comments.
 var matchHTMLCommentsRegex = regexp.MustCompile(`<!--[\s\S]*?-->`)
 
-func removeComments(str string) string {
-	return matchHTMLCommentsRegex.ReplaceAllString(str, "")
-}
-
-var yieldMatchRegex = regexp.MustCompile(`{{-?\s*yield\s*(.*?)\s*-?}}`)
-
-// replaceYieldWithTemplateContent replaces any {{ yield . }} or similar patterns with {{ template "content" . }}.
-func replaceYieldWithTemplateContent(input string) string {
-	return yieldMatchRegex.ReplaceAllString(input, `{{ template "content" $1 }}`)
-}
-
-// Regex pattern to match various forms of {{ template "content" ... }} and {{ yield ... }}
-var layoutPatternRegex = regexp.MustCompile(`{{-?\s*(template\s*"content"\s*[^}]*|yield\s*[^}]*)\s*-?}}`)
-
-// isLayoutTemplate checks if the template contents indicate it is a layout template.
-func isLayoutTemplate(contents string) bool {
-	return layoutPatternRegex.MatchString(contents)
-}
-
 func clearMap[M ~map[K]V, K comparable, V any](m M) {
 	for k := range m {
 		delete(m, k)
@@ -0,0 +1,244 @@
+// Package blockstest provides utilities for golden (snapshot) testing
+// of the templates of a blocks view engine.
+//
+// Usage:
+//
+//	func TestViews(t *testing.T) {
+//		views := blockstest.New(t, map[string]string{
+//			"layouts/main.html": `<main>{{ yield . }}</main>`,
+//			"index.html":        `<h1>{{ .Title }}</h1>`,
+//		})
+//
+//		blockstest.Run(t, views,
+//			blockstest.Case{Template: "index", Layout: "main", Data: map[string]any{"Title": "Hello"}},
+//		)
+//	}
+//
+// The rendered output of each case is compared against the "testdata/<name>.golden" file.
+// Run the tests with the BLOCKSTEST_UPDATE environment variable to create or update the golden files:
+//
+//	BLOCKSTEST_UPDATE=1 go test ./...
+//
+// or with the -update flag, if the test package defines a boolean one:
+//
+//	var update = flag.Bool("update", false, "update the golden files")
+//
+//	go test ./... -update
+package blockstest
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+// UpdateEnv is the environment variable which, when true, updates the golden files, see `Golden`.
+const UpdateEnv = "BLOCKSTEST_UPDATE"
+
+// updating reports whether the golden files should be written instead of compared,
+// through the "update" flag of the test binary, if defined, or the `UpdateEnv` variable.
+// The flag is not registered by the package itself,
+// so it does not conflict with the test package's own -update flag.
+func updating() bool {
+	if f := flag.Lookup("update"); f != nil {
+		if update, err := strconv.ParseBool(f.Value.String()); err == nil && update {
+			return true
+		}
+	}
+
+	update, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	return update
+}
+
+// GoldenDir is the directory of the golden files, relative to the test's package directory.
+var GoldenDir = "testdata"
+
+// New returns a loaded Blocks engine of the "files" which can be a
+// map[string]string of file names and contents, a *blocks.MemoryFileSystem,
+// any other fs.FS or a directory.
+// The optional "configure" functions are called before the engine is loaded,
+// e.g. to register functions or to set the default layout.
+// It fails the test if the engine cannot be loaded.
+func New(tb testing.TB, files any, configure ...func(*blocks.Blocks)) *blocks.Blocks {
+	tb.Helper()
+
+	var fileSystem any
+	switch v := files.(type) {
+	case map[string]string:
+		mfs := blocks.NewMemoryFileSystem()
+		for name, contents := range v {
+			if err := mfs.ParseTemplate(name, []byte(contents), nil); err != nil {
+				tb.Fatalf("blockstest: %s: %v", name, err)
+			}
+		}
+		fileSystem = mfs
+	case fs.FS, string:
+		fileSystem = v
+	default:
+		tb.Fatalf("blockstest: unexpected files type of %T (map[string]string, fs.FS or string)", files)
+	}
+
+	v := blocks.New(fileSystem)
+	for _, fn := range configure {
+		fn(v)
+	}
+
+	if err := v.Load(); err != nil {
+		tb.Fatalf("blockstest: load: %v", err)
+	}
+
+	return v
+}
+
+// Case is a single render of a template (and an optional layout) to be compared
+// against a golden file.
+type Case struct {
+	// Name is the golden file's name, without the ".golden" extension,
+	// relative to the `GoldenDir`. Defaults to the template's name,
+	// followed by "@" and the layout's name, if any, e.g. "index@main".
+	Name     string
+	Template string
+	Layout   string
+	Data     any
+	// Context is passed to `ExecuteTemplateContext`, defaults to context.Background().
+	Context context.Context
+}
+
+func (c Case) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+
+	if c.Layout != "" {
+		return c.Template + "@" + c.Layout
+	}
+
+	return c.Template
+}
+
+// Run renders each of the "cases" as a subtest
+// and compares the output against its golden file, see `Golden`.
+func Run(t *testing.T, v *blocks.Blocks, cases ...Case) {
+	t.Helper()
+
+	for _, c := range cases {
+		t.Run(c.name(), func(t *testing.T) {
+			ctx := c.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			var b strings.Builder
+			if err := v.ExecuteTemplateContext(ctx, &b, c.Template, c.Layout, c.Data); err != nil {
+				t.Fatalf("render: %v", err)
+			}
+
+			Golden(t, c.name(), b.String())
+		})
+	}
+}
+
+// Golden compares the "got" output against the contents of the "name" golden file,
+// after both are normalized through `Normalize`, and fails the test with
+// a readable diff on mismatch. When the tests run with the -update flag
+// or the `UpdateEnv` variable the golden file is written instead.
+func Golden(tb testing.TB, name, got string) {
+	tb.Helper()
+
+	filename := filepath.Join(GoldenDir, filepath.FromSlash(name)+".golden")
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			tb.Fatalf("blockstest: %v", err)
+		}
+
+		if err := os.WriteFile(filename, []byte(got), 0644); err != nil {
+			tb.Fatalf("blockstest: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(filename)
+	if err != nil {
+		tb.Fatalf("blockstest: %v (run the tests with -update to create it)", err)
+	}
+
+	if diff := Diff(Normalize(string(expected)), Normalize(got)); diff != "" {
+		tb.Errorf("output does not match %s (-expected +got):\n%s", filename, diff)
+	}
+}
+
+// tagBoundary matches the (optional) whitespace between two adjacent tags.
+var tagBoundary = regexp.MustCompile(`>\s*<`)
+
+// Normalize splits "s" at the boundaries of adjacent tags, so a page rendered
+// on a single line is compared (and diffed) one element per line,
+// then it trims each line, collapses consecutive whitespace and removes the empty lines,
+// so insignificant whitespace differences do not fail the comparisons.
+func Normalize(s string) string {
+	s = tagBoundary.ReplaceAllString(strings.ReplaceAll(s, "\r\n", "\n"), ">\n<")
+	lines := strings.Split(s, "\n")
+
+	normalized := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			normalized = append(normalized, line)
+		}
+	}
+
+	return strings.Join(normalized, "\n")
+}
+
+// Diff returns a line diff of "expected" and "got", e.g. of their `Normalize` results,
+// lines prefixed with "-" are missing and lines prefixed with "+" are unexpected.
+// It returns an empty string if they are equal.
+func Diff(expected, got string) string {
+	if expected == got {
+		return ""
+	}
+
+	a, b := strings.Split(expected, "\n"), strings.Split(got, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&sb, "  %s\n", a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&sb, "- %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&sb, "+ %s\n", b[j])
+			j++
+		}
+	}
+
+	return sb.String()
+}
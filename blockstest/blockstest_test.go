@@ -0,0 +1,71 @@
+package blockstest_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+	"github.com/kataras/blocks/blockstest"
+)
+
+// The test package's own -update flag, which the blockstest package honors
+// and must not register itself (a "flag redefined" panic).
+var _ = flag.Bool("update", false, "update the golden files")
+
+func TestRun(t *testing.T) {
+	views := blockstest.New(t, map[string]string{
+		"layouts/main.html": `<html>
+	<body>
+		{{ yield . }}
+	</body>
+</html>`,
+		"index.html": `<h1>{{ upper .Title }}</h1>`,
+	}, func(v *blocks.Blocks) {
+		v.Funcs(map[string]any{"upper": strings.ToUpper})
+	})
+
+	blockstest.Run(t, views,
+		blockstest.Case{Template: "index", Layout: "main", Data: map[string]any{"Title": "Hello"}},
+		blockstest.Case{Name: "index-no-layout", Template: "index", Layout: "", Data: map[string]any{"Title": "Hello"}},
+	)
+}
+
+func TestDiff(t *testing.T) {
+	expected := blockstest.Normalize("<ul>\n  <li>a</li>\n\n  <li>b</li>\n</ul>")
+	got := blockstest.Normalize("<ul>\n<li>a</li>\n<li>c</li>\n</ul>   ")
+
+	if diff := blockstest.Diff(expected, expected); diff != "" {
+		t.Fatalf("expected no diff but got:\n%s", diff)
+	}
+
+	expectedDiff := "  <ul>\n  <li>a</li>\n- <li>b</li>\n+ <li>c</li>\n  </ul>\n"
+	if diff := blockstest.Diff(expected, got); diff != expectedDiff {
+		t.Fatalf("expected diff:\n%s\nbut got:\n%s", expectedDiff, diff)
+	}
+
+	// A page rendered on a single line is diffed per element.
+	expected = blockstest.Normalize(`<main><h1>Home</h1> <p>Hello world</p></main>`)
+	got = blockstest.Normalize(`<main><h1>Home</h1><p>Hello there</p></main>`)
+
+	expectedDiff = "  <main>\n  <h1>Home</h1>\n- <p>Hello world</p>\n+ <p>Hello there</p>\n  </main>\n"
+	if diff := blockstest.Diff(expected, got); diff != expectedDiff {
+		t.Fatalf("expected diff:\n%s\nbut got:\n%s", expectedDiff, diff)
+	}
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	goldenDir := blockstest.GoldenDir
+	blockstest.GoldenDir = t.TempDir()
+	defer func() { blockstest.GoldenDir = goldenDir }()
+
+	t.Setenv(blockstest.UpdateEnv, "1")
+	blockstest.Golden(t, "pages/index", "<h1>Index</h1>")
+
+	contents, err := os.ReadFile(filepath.Join(blockstest.GoldenDir, "pages", "index.golden"))
+	if err != nil || string(contents) != "<h1>Index</h1>" {
+		t.Fatalf("expected the golden file to be written but got %q: %v", contents, err)
+	}
+}
@@ -0,0 +1,34 @@
+package blocks_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestCheckTemplate(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/admin.html", []byte(`<admin>{{ yield . }}</admin>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>Index</h1>`), nil)
+	mfs.ParseTemplate("users/index.html", []byte(`<h1>Users</h1>`), nil)
+
+	views := blocks.New(mfs)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := views.CheckTemplate("index", "admin"); err != nil {
+		t.Fatalf("expected the index template with the admin layout to exist but got: %v", err)
+	}
+
+	var tmplErr *blocks.TemplateNotExistError
+	if err := views.CheckTemplate("users/indx", ""); !errors.As(err, &tmplErr) || tmplErr.Name != "users/indx" {
+		t.Fatalf("expected a template error but got %T: %v", err, err)
+	}
+
+	var layoutErr *blocks.LayoutNotExistError
+	if err := views.CheckTemplate("index", "main"); !errors.As(err, &layoutErr) || layoutErr.Name != "main" {
+		t.Fatalf("expected a layout error but got %T: %v", err, err)
+	}
+}
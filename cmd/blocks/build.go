@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/kataras/blocks"
+)
+
+// buildConfig is the routes file of the build command, e.g.
+//
+//	{
+//	  "routes": [
+//	    { "path": "/", "template": "index", "layout": "main", "data": { "Title": "Home" } },
+//	    { "path": "/about", "template": "about", "layout": "main" }
+//	  ],
+//	  "notFound": { "template": "404", "layout": "main" },
+//	  "baseURL": "https://example.com",
+//	  "sitemap": true,
+//	  "search": "/search.json",
+//	  "feeds": [
+//	    { "path": "/feed.xml", "format": "rss", "title": "Blog", "collection": "posts", "entryPath": "/posts/{slug}" }
+//	  ]
+//	}
+//
+// The collections are registered through the -collections flag.
+type buildConfig struct {
+	Routes   []buildRoute `json:"routes"`
+	NotFound *buildRoute  `json:"notFound"`
+	// BaseURL is the absolute URL of the site, required by the sitemap and the feeds.
+	BaseURL string      `json:"baseURL"`
+	Sitemap bool        `json:"sitemap"`
+	Feeds   []buildFeed `json:"feeds"`
+	// Search is the path of the search index, the built-in "search" partial is registered too.
+	Search string `json:"search"`
+}
+
+// buildFeed is a feed of a collection's entries.
+type buildFeed struct {
+	Path string `json:"path"`
+	// Format is "rss" (the default) or "atom".
+	Format      string `json:"format"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Collection  string `json:"collection"`
+	// EntryPath is the path of an entry's page, its {slug} is replaced by the entry's one.
+	EntryPath string `json:"entryPath"`
+}
+
+type buildRoute struct {
+	Path     string `json:"path"`
+	Template string `json:"template"`
+	Layout   string `json:"layout"`
+	Data     any    `json:"data"`
+}
+
+func (r buildRoute) route() blocks.Route {
+	route := blocks.Route{Path: r.Path, Template: r.Template, Layout: r.Layout}
+	if data := r.Data; data != nil {
+		route.Data = func(context.Context) (any, error) { return data, nil }
+	}
+
+	return route
+}
+
+func runBuild(args []string) error {
+	var (
+		ef          engineFlags
+		set         = flag.NewFlagSet("build", flag.ExitOnError)
+		routes      = set.String("routes", "routes.json", "the JSON file of the routes")
+		out         = set.String("out", blocks.DefaultOutputDir, "the output directory")
+		assets      = set.String("assets", "", "the directory of the static files to copy to the output directory")
+		concurrency = set.Int("concurrency", 0, "the maximum number of pages rendered in parallel, defaults to the number of CPUs")
+		incremental = set.Bool("incremental", false, "re-render only the pages whose templates or data changed since the last build")
+		version     = set.String("version", "", "the version of the build, a change re-renders all pages on incremental builds")
+		stubFuncs   = set.Bool("stub-funcs", false, stubFuncsUsage)
+	)
+	ef.register(set)
+	set.Parse(args)
+
+	config, err := readBuildConfig(*routes)
+	if err != nil {
+		return err
+	}
+
+	warnStubFuncs(*stubFuncs)
+	views := ef.engine(*stubFuncs).SearchPartial(config.Search != "")
+	if err = views.Load(); err != nil {
+		return err
+	}
+
+	site := blocks.NewSite(views)
+	site.OutputDir = *out
+	site.Concurrency = *concurrency
+	site.Incremental = *incremental
+	site.Version = *version
+	site.SearchIndex = config.Search
+	if *assets != "" {
+		site.Assets = os.DirFS(*assets)
+	}
+	if config.NotFound != nil {
+		site.NotFound = config.NotFound.route()
+	}
+	for _, r := range config.Routes {
+		site.Route(r.route())
+	}
+	if err = config.files(site, views); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := site.Build(ctx)
+	if report != nil {
+		report.WriteText(os.Stdout)
+	}
+
+	return err
+}
+
+// files adds the sitemap and the feeds of the config to the "site".
+func (c *buildConfig) files(site *blocks.Site, views *blocks.Blocks) error {
+	if (c.Sitemap || len(c.Feeds) > 0) && c.BaseURL == "" {
+		return fmt.Errorf("the baseURL is required by the sitemap and the feeds")
+	}
+
+	if c.Sitemap {
+		site.File("/sitemap.xml", func() ([]byte, error) {
+			return site.Sitemap(c.BaseURL).XML()
+		})
+	}
+
+	for _, f := range c.Feeds {
+		entries := views.Entries(f.Collection)
+		if entries == nil {
+			return fmt.Errorf("feed: %s: collection %q does not exist, see -collections", f.Path, f.Collection)
+		}
+
+		feed := &blocks.Feed{
+			Title:       f.Title,
+			Description: f.Description,
+			Author:      f.Author,
+			BaseURL:     c.BaseURL,
+			Path:        f.Path,
+			Items: entries.Published().FeedItems(func(e *blocks.Entry) string {
+				return strings.ReplaceAll(f.EntryPath, "{slug}", e.Slug)
+			}),
+		}
+
+		switch f.Format {
+		case "", "rss":
+			site.File(f.Path, feed.RSS)
+		case "atom":
+			site.File(f.Path, feed.Atom)
+		default:
+			return fmt.Errorf("feed: %s: unknown format %q, expected rss or atom", f.Path, f.Format)
+		}
+	}
+
+	return nil
+}
+
+func readBuildConfig(filename string) (*buildConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var config buildConfig
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return &config, nil
+}
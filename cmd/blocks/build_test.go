@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildUnknownFuncs(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "views", "index.html"), `<h1>{{ appTitle }}</h1>`)
+	writeTestFile(t, filepath.Join(dir, "routes.json"), `{"routes": [{"path": "/", "template": "index"}]}`)
+
+	args := []string{
+		"-dir", filepath.Join(dir, "views"),
+		"-routes", filepath.Join(dir, "routes.json"),
+		"-out", filepath.Join(dir, "public"),
+	}
+
+	if err := runBuild(args); err == nil || !strings.Contains(err.Error(), `function "appTitle" not defined`) {
+		t.Fatalf("expected the build to fail on the unknown function but got: %v", err)
+	}
+
+	if err := runBuild(append(args, "-stub-funcs")); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "public", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(page) != "<h1></h1>" {
+		t.Fatalf("expected the stubbed function to render as an empty string but got: %s", page)
+	}
+}
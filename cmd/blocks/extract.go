@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kataras/blocks"
+)
+
+func runExtract(args []string) error {
+	var (
+		ef      engineFlags
+		set     = flag.NewFlagSet("extract", flag.ExitOnError)
+		funcs   = set.String("func", blocks.DefaultTranslateFunc, "comma separated names of the translation functions")
+		out     = set.String("out", "./locales", "the directory of the <locale>.json catalogs")
+		locales = set.String("locales", "", "comma separated locales to create or update, defaults to the existing catalogs")
+	)
+	ef.register(set)
+	set.Parse(args)
+
+	messages, err := ef.engine(true).ExtractMessages(context.Background(), splitList(*funcs)...)
+	if err != nil {
+		return err
+	}
+
+	names := splitList(*locales)
+	if len(names) == 0 {
+		matches, err := filepath.Glob(filepath.Join(*out, "*.json"))
+		if err != nil {
+			return err
+		}
+
+		for _, match := range matches {
+			names = append(names, strings.TrimSuffix(filepath.Base(match), ".json"))
+		}
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("no catalogs found in %s, use -locales to create them", *out)
+	}
+
+	if err = os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+
+	fmt.Printf("%d messages extracted from %s\n", len(messages), ef.dir)
+
+	for _, locale := range names {
+		filename := filepath.Join(*out, locale+".json")
+		catalog, err := readCatalogFile(filename)
+		if err != nil {
+			return err
+		}
+
+		result := catalog.Merge(messages)
+		if err = writeCatalogFile(filename, catalog); err != nil {
+			return err
+		}
+
+		fmt.Printf("%s: %d added, %d obsolete, %d untranslated\n", filename, len(result.Added), len(result.Obsolete), len(result.Untranslated))
+		for _, key := range result.Obsolete {
+			fmt.Printf("\tobsolete: %q\n", key)
+		}
+		for _, key := range result.Untranslated {
+			fmt.Printf("\tuntranslated: %q\n", key)
+		}
+	}
+
+	return nil
+}
+
+func readCatalogFile(filename string) (blocks.Catalog, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return make(blocks.Catalog), nil
+		}
+
+		return nil, err
+	}
+	defer f.Close()
+
+	catalog, err := blocks.ReadCatalog(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return catalog, nil
+}
+
+func writeCatalogFile(filename string, catalog blocks.Catalog) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if _, err = catalog.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"unicode"
+)
+
+func runGenerate(args []string) error {
+	var (
+		ef  engineFlags
+		set = flag.NewFlagSet("generate", flag.ExitOnError)
+		pkg = set.String("pkg", os.Getenv("GOPACKAGE"), "the package name of the generated file, defaults to the go:generate one")
+		out = set.String("out", "views_names.go", "the generated Go file")
+	)
+	ef.register(set)
+	set.Parse(args)
+
+	if *pkg == "" {
+		*pkg = "main"
+	}
+
+	views := ef.engine(true)
+	if err := views.Load(); err != nil {
+		return err
+	}
+
+	src, err := generateNames(*pkg, views.TemplateNames(), views.LayoutNames())
+	if err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(*out, src, 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d templates, %d layouts\n", *out, len(views.TemplateNames()), len(views.LayoutNames()))
+	return nil
+}
+
+// generateNames returns the formatted source of the package "pkg"
+// which declares the TemplateName and LayoutName types
+// and a constant of them for each of the template and layout names.
+func generateNames(pkg string, templates, layouts []string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by \"blocks generate\"; DO NOT EDIT.\n\npackage %s\n\n", pkg)
+
+	declared := make(map[string]string)
+	groups := []struct {
+		prefix, kind, doc string
+		names             []string
+	}{
+		{"Template", "template", "a content template or a partial", templates},
+		{"Layout", "layout", "a layout", layouts},
+	}
+
+	for _, group := range groups {
+		typeName := group.prefix + "Name"
+		fmt.Fprintf(&b, "// %s is the name of %s.\ntype %s string\n\n", typeName, group.doc, typeName)
+		fmt.Fprintf(&b, "// String returns the name, e.g. for the engine's methods.\nfunc (n %s) String() string { return string(n) }\n\n", typeName)
+
+		if len(group.names) == 0 {
+			continue
+		}
+
+		b.WriteString("const (\n")
+		for _, name := range group.names {
+			ident := goName(group.prefix, name)
+			if other, exists := declared[ident]; exists {
+				return nil, fmt.Errorf("%s: the names %q and %q both generate it", ident, other, name)
+			}
+			declared[ident] = name
+
+			fmt.Fprintf(&b, "\t// %s is the %q %s.\n\t%s %s = %q\n", ident, name, group.kind, ident, typeName, name)
+		}
+		b.WriteString(")\n\n")
+	}
+
+	return format.Source(b.Bytes())
+}
+
+// goName returns the exported Go identifier of a template name,
+// e.g. "users/index" with the "Template" prefix is "TemplateUsersIndex".
+func goName(prefix, name string) string {
+	ident := []rune(prefix)
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		ident = append(ident, r)
+	}
+
+	return string(ident)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the tests")
+
+func TestGenerateNames(t *testing.T) {
+	templates := []string{"404", "index", "my-page", "partials/footer", "users/index", "users/index.el"}
+	layouts := []string{"main", "themes/dark.main"}
+
+	src, err := generateNames("views", templates, layouts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "names.golden")
+	if *update {
+		if err = os.WriteFile(golden, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(src) != string(expected) {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, src)
+	}
+
+	if formatted, err := format.Source(src); err != nil || string(formatted) != string(src) {
+		t.Fatalf("expected a gofmt-ed source but got: %v\n%s", err, formatted)
+	}
+	if _, err = parser.ParseFile(token.NewFileSet(), "views_names.go", src, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// No layouts still declares their type.
+	if src, err = generateNames("views", []string{"index"}, nil); err != nil || !strings.Contains(string(src), "type LayoutName string") {
+		t.Fatalf("expected the layout name type but got: %v\n%s", err, src)
+	}
+}
+
+func TestGenerateNamesCollision(t *testing.T) {
+	_, err := generateNames("views", []string{"user-list", "user_list"}, nil)
+	if err == nil || err.Error() != `TemplateUserList: the names "user-list" and "user_list" both generate it` {
+		t.Fatalf("expected a collision error but got: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Command blocks is a collection of development tools
+// for projects using the blocks view engine.
+//
+// Usage:
+//
+//	blocks <command> [flags]
+//
+// The commands are:
+//
+//	build      build a static site of the templates
+//	extract    extract translation strings into per-locale JSON catalogs
+//	generate   generate Go constants of the template and layout names
+//	serve      preview the templates with fixture data and reload on changes
+//	vet        report the template and layout names of the Go code which do not exist
+//
+// Run "blocks <command> -h" for the flags of a command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kataras/blocks"
+)
+
+type command struct {
+	name  string
+	short string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"build", "build a static site of the templates", runBuild},
+	{"extract", "extract translation strings into per-locale JSON catalogs", runExtract},
+	{"generate", "generate Go constants of the template and layout names", runGenerate},
+	{"serve", "preview the templates with fixture data and reload on changes", runServe},
+	{"vet", "report the template and layout names of the Go code which do not exist", runVet},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		if err := cmd.run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "blocks %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if name != "help" && name != "-h" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "blocks: unknown command %q\n", name)
+	}
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n\n\tblocks <command> [flags]\n\nThe commands are:\n\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-10s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"blocks <command> -h\" for the flags of a command.\n")
+}
+
+// engineFlags holds the common flags to build a Blocks engine.
+type engineFlags struct {
+	dir       string
+	layoutDir string
+	ext       string
+	// collections are comma separated name=dir pairs, see `blocks.Collection`.
+	collections string
+}
+
+func (f *engineFlags) register(set *flag.FlagSet) {
+	set.StringVar(&f.dir, "dir", "./views", "the views directory")
+	set.StringVar(&f.layoutDir, "layouts", "layouts", "the layouts directory, relative to -dir")
+	set.StringVar(&f.ext, "ext", ".html", "the template file extension")
+	set.StringVar(&f.collections, "collections", "", "comma separated name=dir markdown collections, relative to -dir, e.g. posts=posts (a single name is its directory too)")
+}
+
+// engine returns a new engine of the flags. The functions of the application
+// are not available to the command: a template which calls one fails to load,
+// unless "stubFuncs" is true, then they render as empty strings.
+// Only the commands which do not publish the output should stub them.
+func (f *engineFlags) engine(stubFuncs bool) *blocks.Blocks {
+	views := blocks.New(f.dir).
+		LayoutDir(f.layoutDir).
+		Extension(f.ext)
+	if stubFuncs {
+		views.MissingFuncs(func(...any) string { return "" })
+	}
+
+	for _, pair := range splitList(f.collections) {
+		name, dir, ok := strings.Cut(pair, "=")
+		if !ok {
+			dir = name
+		}
+		views.Collection(name, dir)
+	}
+
+	return views
+}
+
+// stubFuncsUsage is the usage of the -stub-funcs flag of the commands which render pages.
+const stubFuncsUsage = "render the template functions of the application as empty strings, the output is incomplete"
+
+// warnStubFuncs prints a warning about the -stub-funcs flag, if set.
+func warnStubFuncs(stubFuncs bool) {
+	if stubFuncs {
+		fmt.Fprintln(os.Stderr, "blocks: warning: -stub-funcs: the template functions of the application render as empty strings")
+	}
+}
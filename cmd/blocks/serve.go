@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/kataras/blocks"
+)
+
+func runServe(args []string) error {
+	var (
+		ef         engineFlags
+		set        = flag.NewFlagSet("serve", flag.ExitOnError)
+		addr       = set.String("addr", "localhost:8080", "the address to listen on")
+		interval   = set.Duration("interval", blocks.DefaultWatchInterval, "the interval to poll the views directory for changes")
+		suffix     = set.String("fixtures", blocks.DefaultFixturesSuffix, "the suffix of the fixtures files")
+		liveReload = set.Bool("livereload", true, "reload the browser on changes")
+		stubFuncs  = set.Bool("stub-funcs", false, stubFuncsUsage)
+	)
+	ef.register(set)
+	set.Parse(args)
+
+	warnStubFuncs(*stubFuncs)
+	views := ef.engine(*stubFuncs).Reload(*liveReload) // the live reload script is injected on reload mode.
+	if err := views.Load(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	onReload := func(changed []string, err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "reload: %v\n", err)
+			return
+		}
+
+		fmt.Printf("%s reloaded: %s\n", time.Now().Format(time.TimeOnly), strings.Join(changed, ", "))
+	}
+
+	preview := blocks.NewPreview(views)
+	preview.FixturesSuffix = *suffix
+
+	mux := http.NewServeMux()
+	mux.Handle("/", preview)
+
+	if *liveReload {
+		lr := blocks.NewLiveReload(views)
+		lr.Interval = *interval
+		lr.OnReload = onReload
+		mux.Handle(lr.Endpoint, lr)
+		go lr.Watch(ctx)
+	} else {
+		go views.Watch(ctx, *interval, onReload)
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
+	fmt.Printf("Previewing %s on: http://%s\n", ef.dir, *addr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/blocks"
+)
+
+const blocksImportPath = "github.com/kataras/blocks"
+
+// vetMethods are the methods of *blocks.Blocks which render a template,
+// with the argument positions of their template and layout names (-1 for none).
+var vetMethods = map[string][2]int{
+	"ExecuteTemplate":        {1, 2},
+	"ExecuteTemplateContext": {2, 3},
+	"TemplateString":         {0, 1},
+	"PartialFunc":            {0, -1},
+	"Render":                 {2, 3},
+}
+
+func runVet(args []string) error {
+	var (
+		ef      engineFlags
+		set     = flag.NewFlagSet("vet", flag.ExitOnError)
+		mapping = set.String("map", "", "comma separated name=dir views directories of the engines which are not created by a blocks.New literal, e.g. views=./views")
+	)
+	ef.register(set)
+	set.Usage = func() {
+		fmt.Fprintf(set.Output(), "Usage: blocks vet [flags] [packages]\n\n")
+		fmt.Fprintf(set.Output(), "Reports the literal template and layout names of the Go packages which do not exist in their views directory.\n")
+		fmt.Fprintf(set.Output(), "The packages are directories, a \"/...\" suffix includes the subdirectories, the default is \".\".\n\n")
+		set.PrintDefaults()
+	}
+	set.Parse(args)
+
+	engineDirs := make(map[string]string)
+	for _, pair := range splitList(*mapping) {
+		name, dir, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("-map: %q: expected a name=dir pair", pair)
+		}
+		engineDirs[name] = dir
+	}
+
+	patterns := set.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	problems, err := vetPackages(ef, engineDirs, patterns)
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+
+	if n := len(problems); n > 0 {
+		return fmt.Errorf("%d problem(s) found", n)
+	}
+
+	return nil
+}
+
+// vetPackages checks the packages of the "patterns" and returns their problems, in file order.
+// The "mapping" holds the views directories of the engines which are not created by a blocks.New literal.
+func vetPackages(ef engineFlags, mapping map[string]string, patterns []string) ([]string, error) {
+	vet := &vetter{
+		ef:      ef,
+		fset:    token.NewFileSet(),
+		engines: make(map[string]*vetEngine),
+		mapping: mapping,
+	}
+
+	dirs, err := packageDirs(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range dirs {
+		if err = vet.checkPackage(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	return vet.problems, nil
+}
+
+// packageDirs returns the directories of the package "patterns".
+// Like the go command, the directories starting with "." or "_",
+// the "testdata" and "vendor" ones are not included by the "/..." patterns.
+func packageDirs(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		root, recursive := strings.CutSuffix(pattern, "/...")
+		if !recursive {
+			dirs = append(dirs, filepath.Clean(root))
+			continue
+		}
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !d.IsDir() {
+				return nil
+			}
+
+			if name := d.Name(); path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+
+			dirs = append(dirs, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return dirs, nil
+}
+
+// vetter checks the Go call sites of the engines against their views directories.
+type vetter struct {
+	ef       engineFlags
+	fset     *token.FileSet
+	engines  map[string]*vetEngine // views directory -> loaded engine.
+	mapping  map[string]string     // engine variable or field name -> views directory, see -map.
+	problems []string
+}
+
+// vetEngine is a loaded engine of a views directory, or its load error.
+type vetEngine struct {
+	views *blocks.Blocks
+	err   error
+}
+
+// vetPackage holds the declarations of a package the call sites are resolved with.
+type vetPackage struct {
+	dir     string
+	consts  map[string]string // string constants, e.g. the `blocks generate` ones.
+	engines map[string]string // engine variable or field name -> views directory.
+}
+
+func (vet *vetter) checkPackage(dir string) error {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+
+	pkg := &vetPackage{dir: dir, consts: make(map[string]string), engines: make(map[string]string)}
+	type blocksFile struct {
+		file    *ast.File
+		pkgName string // the local name of the blocks package.
+	}
+
+	var files []blocksFile
+	for _, filename := range matches {
+		file, err := parser.ParseFile(vet.fset, filename, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+
+		collectConsts(file, pkg)
+		if pkgName := importName(file, blocksImportPath); pkgName != "" {
+			files = append(files, blocksFile{file, pkgName})
+		}
+	}
+
+	for _, f := range files {
+		collectEngines(f.file, f.pkgName, pkg)
+	}
+
+	for _, f := range files {
+		ast.Inspect(f.file, func(node ast.Node) bool {
+			if call, ok := node.(*ast.CallExpr); ok {
+				vet.checkCall(pkg, f.pkgName, call)
+			}
+			return true
+		})
+	}
+
+	return nil
+}
+
+// importName returns the local name of the "path" import of the "file", if any.
+func importName(file *ast.File, path string) string {
+	for _, spec := range file.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p != path {
+			continue
+		}
+
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+
+		return filepath.Base(path)
+	}
+
+	return ""
+}
+
+// collectConsts collects the string constants of the "file", e.g. the `blocks generate` ones.
+func collectConsts(file *ast.File, pkg *vetPackage) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		decl, ok := node.(*ast.GenDecl)
+		if !ok || decl.Tok != token.CONST {
+			return true
+		}
+
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.ValueSpec)
+			for i, name := range spec.Names {
+				if i < len(spec.Values) {
+					if value, ok := pkg.stringValue(spec.Values[i]); ok {
+						pkg.consts[name.Name] = value
+					}
+				}
+			}
+		}
+
+		return false
+	})
+}
+
+// collectEngines collects the engines created by a blocks.New call of the "file",
+// e.g. views := blocks.New("./views").Reload(true).
+func collectEngines(file *ast.File, pkgName string, pkg *vetPackage) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if i < len(n.Values) {
+					pkg.addEngine(pkgName, name.Name, n.Values[i])
+				}
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) == len(n.Rhs) {
+				for i, lhs := range n.Lhs {
+					pkg.addEngine(pkgName, exprName(lhs), n.Rhs[i])
+				}
+			}
+		case *ast.KeyValueExpr: // &Server{views: blocks.New("./views")}
+			pkg.addEngine(pkgName, exprName(n.Key), n.Value)
+		}
+
+		return true
+	})
+}
+
+func (pkg *vetPackage) addEngine(pkgName, name string, value ast.Expr) {
+	if pkgName == "" || name == "" {
+		return
+	}
+
+	if dir, ok := pkg.newCallDir(pkgName, value); ok && dir != "" {
+		pkg.engines[name] = dir
+	}
+}
+
+// newCallDir reports whether the "expr" is a blocks.New call, or a method chain of it,
+// and returns its literal views directory, if any.
+func (pkg *vetPackage) newCallDir(pkgName string, expr ast.Expr) (string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return "", false
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+
+	if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == pkgName {
+		if sel.Sel.Name != "New" || len(call.Args) != 1 {
+			return "", false
+		}
+
+		dir, _ := pkg.stringValue(call.Args[0])
+		return dir, true
+	}
+
+	return pkg.newCallDir(pkgName, sel.X) // blocks.New("./views").Reload(true)...
+}
+
+// stringValue returns the value of a string literal or a string constant.
+func (pkg *vetPackage) stringValue(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			s, err := strconv.Unquote(e.Value)
+			return s, err == nil
+		}
+	case *ast.Ident:
+		s, ok := pkg.consts[e.Name]
+		return s, ok
+	case *ast.ParenExpr:
+		return pkg.stringValue(e.X)
+	case *ast.CallExpr:
+		if len(e.Args) == 1 { // a conversion, e.g. string(TemplateIndex).
+			if _, ok := e.Fun.(*ast.Ident); ok {
+				return pkg.stringValue(e.Args[0])
+			}
+		}
+
+		// The String method of a generated name, e.g. TemplateIndex.String().
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok && len(e.Args) == 0 && sel.Sel.Name == "String" {
+			return pkg.stringValue(sel.X)
+		}
+	}
+
+	return "", false
+}
+
+// exprName returns the name of a variable or a field expression.
+func exprName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+
+	return ""
+}
+
+func (vet *vetter) checkCall(pkg *vetPackage, pkgName string, call *ast.CallExpr) {
+	var (
+		receiver           ast.Expr
+		method             string
+		tmplArg, layoutArg = -1, -1
+		fun                = call.Fun
+		args               = call.Args
+	)
+
+	if index, ok := fun.(*ast.IndexExpr); ok { // blocks.Bind[T](views, "index", "main")
+		fun = index.X
+	}
+
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+
+	if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == pkgName {
+		if sel.Sel.Name != "Bind" || len(args) == 0 {
+			return
+		}
+
+		receiver, method, args = args[0], "Bind", args[1:]
+		tmplArg, layoutArg = 0, 1
+	} else {
+		positions, ok := vetMethods[sel.Sel.Name]
+		if !ok {
+			return
+		}
+
+		receiver, method = sel.X, sel.Sel.Name
+		tmplArg, layoutArg = positions[0], positions[1]
+	}
+
+	dir, ok := vet.engineDir(pkg, pkgName, receiver)
+	if !ok || tmplArg >= len(args) {
+		return
+	}
+
+	tmplName, ok := pkg.stringValue(args[tmplArg])
+	if !ok {
+		return
+	}
+
+	var layoutName string
+	if layoutArg >= 0 && layoutArg < len(args) {
+		if layoutName, ok = pkg.stringValue(args[layoutArg]); !ok {
+			layoutName = "" // not a literal, check the template only.
+		}
+	}
+
+	engine := vet.engine(pkg, dir)
+	pos := vet.fset.Position(call.Pos())
+	if engine.err != nil {
+		vet.problems = append(vet.problems, fmt.Sprintf("%s: %s: %s: %v", pos, method, dir, engine.err))
+		return
+	}
+
+	if err := engine.views.CheckTemplate(tmplName, layoutName); err != nil {
+		vet.problems = append(vet.problems, fmt.Sprintf("%s: %s: %s: %v", pos, method, dir, err))
+	}
+}
+
+// engineDir returns the views directory of the engine of the "receiver" expression.
+func (vet *vetter) engineDir(pkg *vetPackage, pkgName string, receiver ast.Expr) (string, bool) {
+	if dir, ok := pkg.newCallDir(pkgName, receiver); ok {
+		return dir, dir != ""
+	}
+
+	// blocks.Get(r) is the engine of the -dir flag.
+	if call, ok := receiver.(*ast.CallExpr); ok {
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Get" {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == pkgName {
+				return vet.ef.dir, true
+			}
+		}
+
+		return "", false
+	}
+
+	name := exprName(receiver)
+	if dir, ok := vet.mapping[name]; ok {
+		return dir, true
+	}
+
+	dir, ok := pkg.engines[name]
+	return dir, ok
+}
+
+// engine returns the loaded engine of the "dir" views directory. A relative directory
+// is resolved against the package's directory, or the current working one if it does not exist there.
+func (vet *vetter) engine(pkg *vetPackage, dir string) *vetEngine {
+	if !filepath.IsAbs(dir) {
+		if rel := filepath.Join(pkg.dir, dir); isDir(rel) {
+			dir = rel
+		}
+	}
+	dir = filepath.Clean(dir)
+
+	engine, ok := vet.engines[dir]
+	if !ok {
+		ef := vet.ef
+		ef.dir = dir
+
+		engine = new(vetEngine)
+		if !isDir(dir) {
+			engine.err = errors.New("views directory not found")
+		} else {
+			engine.views = ef.engine(true)
+			engine.err = engine.views.Load()
+		}
+		vet.engines[dir] = engine
+	}
+
+	return engine
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVet(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		mapping  map[string]string
+		problems []string // "line: error" substrings, in order.
+	}{
+		{
+			name: "clean",
+			source: `views := blocks.New("./views")
+	views.ExecuteTemplate(w, "index", "main", nil)
+	views.TemplateString(TemplateIndex, "", nil)
+	blocks.Bind[struct{}](views, "index", LayoutMain)
+	views.ExecuteTemplate(w, TemplateIndex.String(), string(LayoutMain), nil)`,
+		},
+		{
+			name: "missing typed name",
+			source: `views := blocks.New("./views")
+	views.ExecuteTemplate(w, string(TemplateIndex), LayoutAdmin.String(), nil)`,
+			problems: []string{"main.go:12:2: ExecuteTemplate: ./views: layout 'admin' does not exist"},
+		},
+		{
+			name: "missing template",
+			source: `views := blocks.New("./views").Reload(true)
+	views.ExecuteTemplate(w, "indx", "main", nil)`,
+			problems: []string{"main.go:12:2: ExecuteTemplate: ./views: template 'indx' does not exist (did you mean 'index'?)"},
+		},
+		{
+			name: "missing layout",
+			source: `s := &server{views: blocks.New("./views")}
+	s.views.ExecuteTemplateContext(nil, w, TemplateIndex, "admin", nil)
+	blocks.Get(nil).Render(nil, w, "index", "mian", nil)`,
+			problems: []string{
+				"main.go:12:2: ExecuteTemplateContext: ./views: layout 'admin' does not exist",
+				"main.go:13:2: Render: ./views: layout 'mian' does not exist (did you mean 'main'?)",
+			},
+		},
+		{
+			name: "dynamic names",
+			source: `views := blocks.New("./views")
+	name := os.Args[1]
+	views.ExecuteTemplate(w, name, "main", nil)
+	views.ExecuteTemplate(w, "index", name, nil)
+	other.ExecuteTemplate(w, "missing", "", nil)`,
+		},
+		{
+			name: "mapped engine",
+			source: `app.Views.PartialFunc("footer", nil)
+	blocks.New(viewsDir).ExecuteTemplate(w, "footer", "", nil)`,
+			mapping:  map[string]string{"Views": "views"},
+			problems: []string{"main.go:11:2: PartialFunc: views: template 'footer' does not exist"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFile(t, filepath.Join(dir, "views", "index.html"), `<h1>Index</h1>`)
+			writeTestFile(t, filepath.Join(dir, "views", "layouts", "main.html"), `<main>{{ yield . }}</main>`)
+			writeTestFile(t, filepath.Join(dir, "main.go"), `package main
+
+import (
+	"os"
+
+	"github.com/kataras/blocks"
+)
+
+func handle(w *os.File, app *application, other *engine) {
+	var viewsDir = os.Args[0]
+	`+tt.source+`
+}
+
+type name string
+
+func (n name) String() string { return string(n) }
+
+const (
+	TemplateIndex name = "index"
+	LayoutMain    name = "main"
+	LayoutAdmin   name = "admin"
+)
+`)
+
+			// The views directories are relative to the package directory, the current one.
+			ef := engineFlags{dir: "./views", layoutDir: "layouts", ext: ".html"}
+			t.Chdir(dir)
+
+			problems, err := vetPackages(ef, tt.mapping, []string{"."})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(problems) != len(tt.problems) {
+				t.Fatalf("expected %d problems but got %d:\n%s", len(tt.problems), len(problems), strings.Join(problems, "\n"))
+			}
+
+			for i, problem := range problems {
+				if !strings.Contains(problem, tt.problems[i]) {
+					t.Fatalf("expected the problem to contain:\n%s\nbut got:\n%s", tt.problems[i], problem)
+				}
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, filename, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filename, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,349 @@
+package blocks
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"html/template"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// Entry is a markdown file of a content collection, see `Collection`.
+type Entry struct {
+	// Collection is the name of the collection the entry belongs to.
+	Collection string
+	// Filename is the path of the file, relative to the engine's file system, e.g. "posts/hello.md".
+	Filename string
+	// Slug is the "slug" front matter value or the file path
+	// relative to the collection's directory without its extension, e.g. "hello".
+	Slug string
+	// Title is the "title" front matter value or the text of the first "# heading".
+	Title string
+	// Date is the "date" front matter value.
+	Date time.Time
+	// Tags is the "tags" front matter list.
+	Tags []string
+	// Draft is the "draft" front matter value.
+	Draft bool
+	// Summary is the rendered "summary" front matter value,
+	// the body above a <!--more--> line or the first paragraph of the body.
+	Summary template.HTML
+	// Body is the rendered markdown.
+	Body template.HTML
+	// FrontMatter holds all front matter values, including the above.
+	FrontMatter map[string]any
+}
+
+// Collection is a list of markdown entries.
+// Its methods never modify the receiver, they return a new collection instead.
+type Collection []*Entry
+
+// collectionDir is a registered content collection, see `Blocks.Collection`.
+type collectionDir struct {
+	name string
+	dir  string
+}
+
+// Collection registers a content collection of the markdown files
+// under the "dir" directory of the engine's file system, e.g. Collection("posts", "posts").
+// Its ".md" files are loaded as data instead of templates on `Load`,
+// the rest of its files are still templates,
+// see `Entries` and the {{ collection "name" }} template function.
+// It panics if the "dir" is empty or the root directory of the file system.
+func (v *Blocks) Collection(name, dir string) *Blocks {
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	if dir == "" {
+		panic(fmt.Errorf("blocks: collection %q: expected a directory under the root of the file system", name))
+	}
+
+	v.collectionDirs = append(v.collectionDirs, collectionDir{name: name, dir: dir})
+	return v
+}
+
+// Entries returns the entries of the "name" collection, newest first.
+// It returns nil if the collection is not registered.
+func (v *Blocks) Entries(name string) Collection {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.collections[name]
+}
+
+// collectionFunc is the {{ collection "name" }} template function.
+func (v *Blocks) collectionFunc(name string) (Collection, error) {
+	v.mu.RLock()
+	entries, ok := v.collections[name]
+	v.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("blocks: collection '%s' does not exist", name)
+	}
+
+	return entries, nil
+}
+
+// inCollection reports whether the "filename" is an entry of a content collection.
+func (v *Blocks) inCollection(filename string) bool {
+	if path.Ext(filename) != ".md" {
+		return false
+	}
+
+	for _, c := range v.collectionDirs {
+		if strings.HasPrefix(filename, c.dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadCollections reads the entries of the registered collections.
+func (v *Blocks) loadCollections(ctx context.Context) error {
+	var sources []string // for the collections digest.
+	for _, c := range v.collectionDirs {
+		filesMap, err := readFiles(ctx, v.fs, c.dir)
+		if err != nil {
+			return fmt.Errorf("blocks: collection: %s: %w", c.name, err)
+		}
+
+		entries := make(Collection, 0, len(filesMap))
+		for filename, data := range filesMap {
+			if path.Ext(filename) != ".md" {
+				continue
+			}
+
+			entry, err := v.parseEntry(c, filename, data)
+			if err != nil {
+				return fmt.Errorf("blocks: collection: %s: %s: %w", c.name, entry.Filename, err)
+			}
+
+			entries = append(entries, entry)
+			sources = append(sources, entry.Filename+"\n"+string(data))
+		}
+
+		v.collections[c.name] = entries.SortBy("-date")
+	}
+
+	sort.Strings(sources)
+	v.collectionsDigest = digest([]byte(strings.Join(sources, "\n")))
+	return nil
+}
+
+var (
+	moreSeparator       = regexp.MustCompile(`(?m)^\s*<!--\s*more\s*-->\s*$`)
+	firstParagraphRegex = regexp.MustCompile(`(?s)<p>.*?</p>`)
+)
+
+// parseEntry parses the "filename" markdown file, relative to the collection's directory.
+func (v *Blocks) parseEntry(c collectionDir, filename string, data []byte) (*Entry, error) {
+	entry := &Entry{
+		Collection: c.name,
+		Filename:   path.Join(c.dir, filename),
+		Slug:       strings.TrimSuffix(filename, path.Ext(filename)),
+	}
+
+	frontMatter, body, err := splitFrontMatter(bytes.TrimSpace(data))
+	if err != nil {
+		return entry, err
+	}
+	if frontMatter == nil {
+		frontMatter = make(map[string]any)
+	}
+	entry.FrontMatter = frontMatter
+
+	if slug, ok := frontMatter["slug"].(string); ok && slug != "" {
+		entry.Slug = slug
+	}
+	entry.Title, _ = frontMatter["title"].(string)
+	entry.Date, _ = frontMatter["date"].(time.Time)
+	entry.Draft, _ = frontMatter["draft"].(bool)
+	switch tags := frontMatter["tags"].(type) {
+	case string:
+		entry.Tags = []string{tags}
+	case []any:
+		for _, tag := range tags {
+			entry.Tags = append(entry.Tags, fmt.Sprint(tag))
+		}
+	}
+
+	if entry.Title == "" {
+		entry.Title = markdownTitle(body)
+	}
+	if entry.Title == "" {
+		entry.Title = path.Base(entry.Slug)
+	}
+
+	render := v.markdownParser()
+	html, err := render(body)
+	if err != nil {
+		return entry, err
+	}
+	entry.Body = template.HTML(html)
+
+	if summary, ok := frontMatter["summary"].(string); ok {
+		html, err = render([]byte(summary))
+	} else if loc := moreSeparator.FindIndex(body); loc != nil {
+		html, err = render(body[:loc[0]])
+	} else {
+		html = firstParagraphRegex.Find(html)
+	}
+	if err != nil {
+		return entry, err
+	}
+	entry.Summary = template.HTML(bytes.TrimSpace(html))
+
+	return entry, nil
+}
+
+// markdownParser returns the ".md" extension parser, see `Extensions`.
+func (v *Blocks) markdownParser() ExtensionParser {
+	if parser := v.extensionHandler[".md"]; parser != nil {
+		return parser
+	}
+
+	return func(b []byte) ([]byte, error) { return blackfriday.Run(b), nil }
+}
+
+// markdownTitle returns the text of the first "# heading" line of the markdown "body".
+func markdownTitle(body []byte) string {
+	for line := range strings.Lines(string(body)) {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+
+	return ""
+}
+
+// Get returns the entry of the "slug", nil if it does not exist.
+func (c Collection) Get(slug string) *Entry {
+	for _, entry := range c {
+		if entry.Slug == slug {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// Filter returns the entries "keep" reports true for.
+func (c Collection) Filter(keep func(*Entry) bool) Collection {
+	var entries Collection
+	for _, entry := range c {
+		if keep(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// Where returns the entries whose "key" front matter value equals to the "value"
+// or, for lists, contains it, e.g. {{ range (collection "posts").Where "author" "kataras" }}.
+func (c Collection) Where(key string, value any) Collection {
+	want := fmt.Sprint(value)
+	return c.Filter(func(entry *Entry) bool {
+		switch got := entry.FrontMatter[key].(type) {
+		case nil:
+			return false
+		case []any:
+			return slices.ContainsFunc(got, func(item any) bool { return fmt.Sprint(item) == want })
+		default:
+			return fmt.Sprint(got) == want
+		}
+	})
+}
+
+// Tagged returns the entries of the "tag".
+func (c Collection) Tagged(tag string) Collection {
+	return c.Filter(func(entry *Entry) bool { return slices.Contains(entry.Tags, tag) })
+}
+
+// Published returns the entries which are not drafts.
+func (c Collection) Published() Collection {
+	return c.Filter(func(entry *Entry) bool { return !entry.Draft })
+}
+
+// Limit returns the first "n" entries.
+func (c Collection) Limit(n int) Collection {
+	if n < len(c) {
+		return c[:max(n, 0)]
+	}
+
+	return c
+}
+
+// Sort returns the entries sorted by "cmp", see `slices.SortStableFunc`.
+func (c Collection) Sort(cmp func(a, b *Entry) int) Collection {
+	entries := slices.Clone(c)
+	slices.SortStableFunc(entries, cmp)
+	return entries
+}
+
+// SortBy returns the entries sorted by the "key": "date", "title", "slug" or any other front matter key.
+// A "-" prefix sorts them in descending order, e.g. {{ range (collection "posts").SortBy "-date" }}.
+// Entries of equal keys are sorted by their title.
+func (c Collection) SortBy(key string) Collection {
+	key, desc := strings.CutPrefix(key, "-")
+
+	var compare func(a, b *Entry) int
+	switch key {
+	case "date":
+		compare = func(a, b *Entry) int { return a.Date.Compare(b.Date) }
+	case "title":
+		compare = func(a, b *Entry) int { return cmp.Compare(a.Title, b.Title) }
+	case "slug":
+		compare = func(a, b *Entry) int { return cmp.Compare(a.Slug, b.Slug) }
+	default:
+		compare = func(a, b *Entry) int { return compareValues(a.FrontMatter[key], b.FrontMatter[key]) }
+	}
+
+	return c.Sort(func(a, b *Entry) int {
+		n := compare(a, b)
+		if desc {
+			n = -n
+		}
+		if n == 0 {
+			n = cmp.Or(cmp.Compare(a.Title, b.Title), cmp.Compare(a.Slug, b.Slug))
+		}
+
+		return n
+	})
+}
+
+// compareValues compares two front matter values of the same type, missing values first.
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case nil:
+		if b == nil {
+			return 0
+		}
+		return -1
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b)
+		}
+	case int:
+		if b, ok := b.(int); ok {
+			return cmp.Compare(a, b)
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			return cmp.Compare(a, b)
+		}
+	}
+
+	if b == nil {
+		return 1
+	}
+
+	return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
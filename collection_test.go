@@ -0,0 +1,115 @@
+package blocks_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestCollection(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("index.html", []byte(`{{ range (collection "posts").Tagged "go" }}<a href="/posts/{{ .Slug }}">{{ .Title }}</a>{{ end }}`), nil)
+	mfs.ParseTemplate("posts/first.md", []byte(`---
+title: First
+date: 2024-01-02
+tags: [go, web]
+---
+The first post.
+
+More of it.`), nil)
+	mfs.ParseTemplate("posts/second.md", []byte(`---
+date: 2024-03-04
+tags:
+  - go
+draft: true
+---
+# Second Post
+
+Intro.
+
+<!--more-->
+
+The rest.`), nil)
+	mfs.ParseTemplate("posts/2024/third.md", []byte(`---
+slug: third-one
+date: 2024-02-03
+summary: A *short* one.
+---
+Third.`), nil)
+
+	mfs.ParseTemplate("posts/list.html", []byte(`{{ len (collection "posts") }}`), nil)
+
+	views := blocks.New(mfs).Collection("posts", "posts")
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if names := views.TemplateNames(); len(names) != 2 || names[0] != "index" || names[1] != "posts/list" {
+		t.Fatalf("expected the collection files not to be templates but got: %v", names)
+	}
+
+	posts := views.Entries("posts")
+	var slugs []string
+	for _, entry := range posts {
+		slugs = append(slugs, entry.Slug)
+	}
+	if got := strings.Join(slugs, ","); got != "second,third-one,first" {
+		t.Fatalf("expected the entries newest first but got: %s", got)
+	}
+
+	second := posts.Get("second")
+	if second.Title != "Second Post" || !second.Draft || second.Filename != "posts/second.md" || len(second.Tags) != 1 {
+		t.Fatalf("unexpected entry: %#v", second)
+	}
+	if got := string(second.Summary); got != "<h1>Second Post</h1>\n\n<p>Intro.</p>" {
+		t.Fatalf("unexpected summary: %q", got)
+	}
+	if !strings.Contains(string(second.Body), "<p>The rest.</p>") {
+		t.Fatalf("unexpected body: %q", second.Body)
+	}
+
+	if got := string(posts.Get("first").Summary); got != "<p>The first post.</p>" {
+		t.Fatalf("unexpected summary: %q", got)
+	}
+	if got := string(posts.Get("third-one").Summary); got != "<p>A <em>short</em> one.</p>" {
+		t.Fatalf("unexpected summary: %q", got)
+	}
+
+	if got := posts.Published().SortBy("title"); len(got) != 2 || got[0].Slug != "first" || got[1].Title != "third-one" {
+		t.Fatalf("unexpected published entries: %v", got)
+	}
+	if got := posts.Where("tags", "web").Limit(5); len(got) != 1 || got[0].Slug != "first" {
+		t.Fatalf("unexpected entries: %v", got)
+	}
+
+	contents, err := views.TemplateString("index", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<a href="/posts/second">Second Post</a><a href="/posts/first">First</a>`; contents != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, contents)
+	}
+
+	mfs.ParseTemplate("missing.html", []byte(`{{ range collection "pages" }}{{ end }}`), nil)
+	if err = views.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = views.TemplateString("missing", "", nil); err == nil || !strings.Contains(err.Error(), "collection 'pages' does not exist") {
+		t.Fatalf("expected a missing collection error but got: %v", err)
+	}
+}
+
+func TestCollectionRootDir(t *testing.T) {
+	for _, dir := range []string{"", ".", "/"} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("expected a panic for the %q directory", dir)
+				}
+			}()
+
+			blocks.New(blocks.NewMemoryFileSystem()).Collection("pages", dir)
+		}()
+	}
+}
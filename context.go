@@ -0,0 +1,158 @@
+package blocks
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+	"sync"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// ContextFuncs registers functions which are resolved per execution.
+// Each value must be a function which accepts a context.Context as its first input argument,
+// e.g. func(ctx context.Context) any or func(ctx context.Context, name string) (string, error).
+// The templates call them without the context argument, e.g. {{ csrf }},
+// the engine passes the context given to `ExecuteTemplateContext`
+// (or context.Background() on `ExecuteTemplate`).
+//
+// Useful for request-scoped values such as the current user,
+// a CSRF token, the request path or a CSP nonce.
+// It must be called before the engine is loaded.
+// It panics if a value in the map is not a function
+// with a context.Context as its first input argument.
+func (v *Blocks) ContextFuncs(funcMap template.FuncMap) *Blocks {
+	if v.contextFuncs == nil {
+		v.contextFuncs = make(map[string]reflect.Value)
+	}
+
+	for name, fn := range funcMap {
+		fnValue := reflect.ValueOf(fn)
+		if fnValue.Kind() != reflect.Func || fnValue.Type().NumIn() == 0 || fnValue.Type().In(0) != contextType {
+			panic(fmt.Errorf("blocks: context func %q: expected a function with a context.Context first input argument but got %T", name, fn))
+		}
+
+		v.contextFuncs[name] = fnValue
+	}
+
+	return v
+}
+
+// bindContextFuncs returns the template functions of the registered
+// context funcs, the "ctx" function is called to resolve the context of each call.
+func (v *Blocks) bindContextFuncs(ctx func() context.Context) template.FuncMap {
+	funcs := make(template.FuncMap, len(v.contextFuncs))
+	for name, fnValue := range v.contextFuncs {
+		funcs[name] = bindContextFunc(fnValue, ctx)
+	}
+
+	return funcs
+}
+
+// bindContextFunc makes a function of the same signature as "fn"
+// without its first context.Context input argument.
+func bindContextFunc(fn reflect.Value, ctx func() context.Context) any {
+	typ := fn.Type()
+
+	in := make([]reflect.Type, 0, typ.NumIn()-1)
+	for i := 1; i < typ.NumIn(); i++ {
+		in = append(in, typ.In(i))
+	}
+
+	out := make([]reflect.Type, 0, typ.NumOut())
+	for i := 0; i < typ.NumOut(); i++ {
+		out = append(out, typ.Out(i))
+	}
+
+	bound := reflect.MakeFunc(reflect.FuncOf(in, out, typ.IsVariadic()), func(args []reflect.Value) []reflect.Value {
+		c := ctx()
+		if c.Err() != nil {
+			// Stop a render which writes nothing, the template package reports the panic as an error.
+			panic(contextErr(c))
+		}
+
+		args = append([]reflect.Value{reflect.ValueOf(c)}, args...)
+		if typ.IsVariadic() {
+			return fn.CallSlice(args)
+		}
+
+		return fn.Call(args)
+	})
+
+	return bound.Interface()
+}
+
+// execution is a clone of a loaded template
+// whose context-aware functions are bound to the context of the current execution.
+// It is used by a single execution at a time, see `acquireExecution`.
+type execution struct {
+	tmpl *template.Template
+	ctx  context.Context
+}
+
+func (e *execution) context() context.Context {
+	return e.ctx
+}
+
+// needsExecution reports whether templates should be executed
+// through a bound clone instead of the loaded one,
+// e.g. so partials inherit the context of the render they are called from.
+func (v *Blocks) needsExecution() bool {
+	return len(v.contextFuncs) > 0 || len(v.observers) > 0 || v.sandbox != nil
+}
+
+// acquireExecution returns an execution of the loaded "tmpl" (the prototype)
+// bound to "ctx". The loaded templates are never executed themselves,
+// their clones are kept in a pool per template so the escaping
+// and cloning costs are paid once per concurrent execution, not per request.
+// The caller should call `releaseExecution` when done.
+func (v *Blocks) acquireExecution(ctx context.Context, tmpl *template.Template) (*execution, error) {
+	poolValue, ok := v.executions.Load(tmpl)
+	if !ok {
+		poolValue, _ = v.executions.LoadOrStore(tmpl, new(sync.Pool))
+	}
+	pool := poolValue.(*sync.Pool)
+
+	if e, ok := pool.Get().(*execution); ok {
+		e.ctx = ctx
+		return e, nil
+	}
+
+	clone, err := tmpl.Clone()
+	if err != nil {
+		// The loaded template was executed itself, e.g. the context funcs were registered after a render.
+		return nil, fmt.Errorf("blocks: %s: the context funcs, observers or sandbox were registered after the template was executed, load the engine again: %w", tmpl.Name(), err)
+	}
+
+	e := &execution{ctx: ctx}
+	funcs := v.bindContextFuncs(e.context)
+	funcs["partial"] = func(partialName string, data any) (template.HTML, error) {
+		return v.partial(e.ctx, partialName, data)
+	}
+	e.tmpl = clone.Funcs(funcs)
+	return e, nil
+}
+
+func (v *Blocks) releaseExecution(tmpl *template.Template, e *execution) {
+	e.ctx = nil
+	if poolValue, ok := v.executions.Load(tmpl); ok {
+		poolValue.(*sync.Pool).Put(e)
+	}
+}
+
+// contextWriter stops the execution of a template
+// when its context is canceled or its deadline is exceeded.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (w *contextWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return w.w.Write(p)
+}
@@ -0,0 +1,100 @@
+package blocks_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+type userKey struct{}
+
+func TestExecuteTemplateContext(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<nav>{{ user }}</nav>{{ yield . }}`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>{{ greet .Greeting }}</h1>{{ partial "partials/footer" . }}`), nil)
+	mfs.ParseTemplate("partials/footer.html", []byte(`<footer>{{ user }}</footer>`), nil)
+
+	views := blocks.New(mfs).ContextFuncs(map[string]any{
+		"user": func(ctx context.Context) string {
+			if name, ok := ctx.Value(userKey{}).(string); ok {
+				return name
+			}
+			return "guest"
+		},
+		"greet": func(ctx context.Context, greeting string) (string, error) {
+			name, _ := ctx.Value(userKey{}).(string)
+			return fmt.Sprintf("%s, %s!", greeting, name), nil
+		},
+	})
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			name := fmt.Sprintf("user%d", i)
+			ctx := context.WithValue(context.Background(), userKey{}, name)
+
+			var b strings.Builder
+			if err := views.ExecuteTemplateContext(ctx, &b, "index", "main", map[string]any{"Greeting": "Hello"}); err != nil {
+				t.Error(err)
+				return
+			}
+
+			expected := fmt.Sprintf("<nav>%s</nav><h1>Hello, %s!</h1><footer>%s</footer>", name, name, name)
+			if got := b.String(); got != expected {
+				t.Errorf("expected:\n%s\nbut got:\n%s", expected, got)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got, err := views.TemplateString("partials/footer", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "<footer>guest</footer>"; got != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = views.ExecuteTemplateContext(ctx, new(strings.Builder), "index", "main", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled error but got: %v", err)
+	}
+}
+
+func TestContextFuncsAfterRender(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("index.html", []byte(`<h1>Index</h1>`), nil)
+
+	views := blocks.New(mfs)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := views.TemplateString("index", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	views.ContextFuncs(map[string]any{"user": func(context.Context) string { return "" }})
+	if _, err := views.TemplateString("index", "", nil); err == nil || !strings.Contains(err.Error(), "load the engine again") {
+		t.Fatalf("expected a load again error but got: %v", err)
+	}
+
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if contents, err := views.TemplateString("index", "", nil); err != nil || contents != "<h1>Index</h1>" {
+		t.Fatalf("expected the index template after a load but got %q: %v", contents, err)
+	}
+}
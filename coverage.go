@@ -0,0 +1,390 @@
+package blocks
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template/parse"
+)
+
+// coverFuncName is the name of the function
+// the instrumented templates call to record the execution of a block.
+const coverFuncName = "_blocksCover"
+
+// Coverage turns on the coverage instrumentation mode, for tests.
+// On `Load`, every template's blocks, i.e. the body of a template, of a define/block
+// and of each if/else/range/with branch, are instrumented to record their executions,
+// see `CoverageReport`. The counters are reset on each load.
+//
+// The instrumentation adds a small overhead to each render,
+// it should not be enabled on production.
+func (v *Blocks) Coverage(b bool) *Blocks {
+	if !b {
+		v.coverage = nil
+		return v
+	}
+
+	if v.coverage == nil {
+		v.coverage = new(coverage)
+	}
+
+	return v
+}
+
+// coverage holds the instrumented blocks of the loaded templates.
+type coverage struct {
+	mu     sync.RWMutex
+	blocks []*coverBlock
+	// sources of the template files, keyed by their file name.
+	sources map[string]string
+}
+
+// coverBlock is an instrumented list of template nodes.
+type coverBlock struct {
+	filename string
+	kind     string // template, if, else, range, with.
+	line     int    // the line the block starts at.
+	lines    []int  // the lines of the block's own nodes.
+	count    atomic.Uint64
+}
+
+func (c *coverage) reset() {
+	c.mu.Lock()
+	c.blocks = nil
+	c.sources = make(map[string]string)
+	c.mu.Unlock()
+}
+
+// cover is the template function which records the execution of a block.
+func (c *coverage) cover(id int) string {
+	c.mu.RLock()
+	if id >= 0 && id < len(c.blocks) {
+		c.blocks[id].count.Add(1)
+	}
+	c.mu.RUnlock()
+	return ""
+}
+
+// instrument injects a variable declaration action, which calls the cover function,
+// at the start of each block of the "file". A declaration produces no output,
+// so the escaping context of the templates is not affected.
+// The blocks' lines are mapped to the file's source.
+func (c *coverage) instrument(file *templateFile) error {
+	contents := file.contents
+	trees, err := file.parseTrees()
+	if err != nil {
+		return mapError(err, []*templateFile{file})
+	}
+
+	type insertion struct {
+		pos int
+		id  int
+	}
+	var insertions []insertion
+
+	c.mu.Lock()
+	c.sources[file.filename] = file.source
+
+	addBlock := func(kind string, list *parse.ListNode) {
+		if list == nil {
+			return
+		}
+
+		pos := int(list.Pos)
+		// The "else if" and "else with" lists start inside the else action,
+		// their nested if/with node is instrumented instead.
+		isText := len(list.Nodes) > 0 && list.Nodes[0].Type() == parse.NodeText && int(list.Nodes[0].Position()) == pos
+		if !isText && !strings.HasPrefix(contents[pos:], file.left) {
+			return
+		}
+
+		line, _ := file.position(pos)
+		block := &coverBlock{
+			filename: file.filename,
+			kind:     kind,
+			line:     line,
+			lines:    listLines(file, list),
+		}
+		insertions = append(insertions, insertion{pos: pos, id: len(c.blocks)})
+		c.blocks = append(c.blocks, block)
+	}
+
+	names := make([]string, 0, len(trees))
+	for name := range trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tree := trees[name]
+		if tree.Root == nil || parse.IsEmptyTree(tree.Root) {
+			continue
+		}
+
+		addBlock("template", tree.Root)
+		walkNodes(tree.Root, func(node parse.Node) {
+			switch n := node.(type) {
+			case *parse.IfNode:
+				addBlock("if", n.List)
+				addBlock("else", n.ElseList)
+			case *parse.RangeNode:
+				addBlock("range", n.List)
+				addBlock("else", n.ElseList)
+			case *parse.WithNode:
+				addBlock("with", n.List)
+				addBlock("else", n.ElseList)
+			}
+		})
+	}
+	c.mu.Unlock()
+
+	sort.Slice(insertions, func(i, j int) bool {
+		return insertions[i].pos < insertions[j].pos
+	})
+
+	edits := make([]sourceEdit, 0, len(insertions))
+	for _, ins := range insertions {
+		action := fmt.Sprintf("%s $_ := %s %d %s", file.left, coverFuncName, ins.id, file.right)
+		edits = append(edits, sourceEdit{start: ins.pos, end: ins.pos, text: action})
+	}
+	file.edit(edits...)
+
+	return nil
+}
+
+// listLines returns the source lines of the "list"'s own nodes,
+// the text nodes count only for their non-space lines.
+func listLines(file *templateFile, list *parse.ListNode) []int {
+	var lines []int
+	for _, node := range list.Nodes {
+		pos := int(node.Position())
+		if text, ok := node.(*parse.TextNode); ok {
+			for _, textLine := range strings.SplitAfter(string(text.Text), "\n") {
+				if strings.TrimSpace(textLine) != "" {
+					line, _ := file.position(pos)
+					lines = appendUniqueInt(lines, line)
+				}
+				pos += len(textLine)
+			}
+			continue
+		}
+
+		line, _ := file.position(pos)
+		lines = appendUniqueInt(lines, line)
+	}
+
+	return lines
+}
+
+func appendUniqueInt(list []int, n int) []int {
+	for _, item := range list {
+		if item == n {
+			return list
+		}
+	}
+
+	return append(list, n)
+}
+
+// CoverageReport is the coverage of the templates, see `Blocks.CoverageReport`.
+type CoverageReport struct {
+	// Files are sorted by their file name.
+	Files []FileCoverage
+}
+
+// FileCoverage is the coverage of a single template file.
+type FileCoverage struct {
+	Filename string
+	Blocks   []BlockCoverage
+	// Lines maps each covered line to its execution count,
+	// a line executed zero times is not covered.
+	// Lines which do not belong to any block are missing.
+	Lines map[int]uint64
+	// Source is the file's source, as it was parsed.
+	Source string
+}
+
+// BlockCoverage is the coverage of a single block.
+type BlockCoverage struct {
+	Kind  string // template, if, else, range or with.
+	Line  int
+	Count uint64
+}
+
+// CoverageReport returns the coverage of the loaded templates,
+// mapped back to their source files.
+// It returns nil if the `Coverage` mode is off.
+func (v *Blocks) CoverageReport() *CoverageReport {
+	if v.coverage == nil {
+		return nil
+	}
+
+	c := v.coverage
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	files := make(map[string]*FileCoverage)
+	for _, block := range c.blocks {
+		file, ok := files[block.filename]
+		if !ok {
+			file = &FileCoverage{
+				Filename: block.filename,
+				Lines:    make(map[int]uint64),
+				Source:   c.sources[block.filename],
+			}
+			files[block.filename] = file
+		}
+
+		count := block.count.Load()
+		file.Blocks = append(file.Blocks, BlockCoverage{Kind: block.kind, Line: block.line, Count: count})
+		for _, line := range block.lines {
+			if existing, ok := file.Lines[line]; !ok || count > existing {
+				file.Lines[line] = count
+			}
+		}
+	}
+
+	report := &CoverageReport{Files: make([]FileCoverage, 0, len(files))}
+	for _, file := range files {
+		sort.SliceStable(file.Blocks, func(i, j int) bool {
+			return file.Blocks[i].Line < file.Blocks[j].Line
+		})
+		report.Files = append(report.Files, *file)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Filename < report.Files[j].Filename
+	})
+
+	return report
+}
+
+// ResetCoverage zeroes the coverage counters, e.g. between test runs.
+func (v *Blocks) ResetCoverage() {
+	if v.coverage == nil {
+		return
+	}
+
+	v.coverage.mu.RLock()
+	for _, block := range v.coverage.blocks {
+		block.count.Store(0)
+	}
+	v.coverage.mu.RUnlock()
+}
+
+// Percent returns the percentage of the covered blocks.
+func (f FileCoverage) Percent() float64 {
+	covered := 0
+	for _, block := range f.Blocks {
+		if block.Count > 0 {
+			covered++
+		}
+	}
+
+	return percent(covered, len(f.Blocks))
+}
+
+// Percent returns the percentage of the covered blocks of all files.
+func (r *CoverageReport) Percent() float64 {
+	covered, total := 0, 0
+	for _, file := range r.Files {
+		for _, block := range file.Blocks {
+			if block.Count > 0 {
+				covered++
+			}
+		}
+		total += len(file.Blocks)
+	}
+
+	return percent(covered, total)
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+
+	return 100 * float64(n) / float64(total)
+}
+
+// WriteText writes a plain text summary of the report to "w":
+// the coverage percentage of each file followed by its uncovered blocks.
+func (r *CoverageReport) WriteText(w io.Writer) error {
+	for _, file := range r.Files {
+		if _, err := fmt.Fprintf(w, "%s\t%.1f%%\n", file.Filename, file.Percent()); err != nil {
+			return err
+		}
+
+		for _, block := range file.Blocks {
+			if block.Count > 0 {
+				continue
+			}
+
+			if _, err := fmt.Fprintf(w, "\t%s:%d: %s block not covered\n", file.Filename, block.Line, block.Kind); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "total\t%.1f%%\n", r.Percent())
+	return err
+}
+
+var coverageHTMLTemplate = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"lines": func(file FileCoverage) []coverageLine {
+		sourceLines := strings.Split(file.Source, "\n")
+		lines := make([]coverageLine, len(sourceLines))
+		for i, text := range sourceLines {
+			count, tracked := file.Lines[i+1]
+			lines[i] = coverageLine{Number: i + 1, Text: text, Count: count, Tracked: tracked}
+		}
+		return lines
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Templates Coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table.source { border-collapse: collapse; font-family: monospace; white-space: pre; width: 100%; }
+table.source td { padding: 0 .5em; }
+td.num, td.count { color: #888; text-align: right; user-select: none; }
+tr.covered { background: #e6ffec; }
+tr.uncovered { background: #ffebe9; }
+</style>
+</head>
+<body>
+<h1>Templates Coverage: {{ printf "%.1f" .Percent }}%</h1>
+<ul>
+{{- range $i, $file := .Files }}
+<li><a href="#file-{{ $i }}">{{ $file.Filename }}</a> {{ printf "%.1f" $file.Percent }}%</li>
+{{- end }}
+</ul>
+{{- range $i, $file := .Files }}
+<h2 id="file-{{ $i }}">{{ $file.Filename }} ({{ printf "%.1f" $file.Percent }}%)</h2>
+<table class="source">
+{{- range lines $file }}
+<tr class="{{ if .Tracked }}{{ if .Count }}covered{{ else }}uncovered{{ end }}{{ end }}"><td class="num">{{ .Number }}</td><td class="count">{{ if .Tracked }}{{ .Count }}{{ end }}</td><td>{{ .Text }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+</body>
+</html>
+`))
+
+type coverageLine struct {
+	Number  int
+	Text    string
+	Count   uint64
+	Tracked bool
+}
+
+// WriteHTML writes an HTML view of the report to "w",
+// which shows each file's source with its covered and uncovered lines highlighted.
+func (r *CoverageReport) WriteHTML(w io.Writer) error {
+	return coverageHTMLTemplate.Execute(w, r)
+}
@@ -0,0 +1,89 @@
+package blocks_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestCoverage(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<html>
+<script>var user = {{ .User }};</script>
+{{ yield . }}
+</html>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`{{ if .User }}
+	<p>Hello, {{ .User }}</p>
+{{ else if .Guest }}
+	<p>Hello, guest</p>
+{{ else }}
+	<p>Please login</p>
+{{ end }}
+<ul>
+{{ range .Items }}
+	<li>{{ . }}</li>
+{{ else }}
+	<li>No items</li>
+{{ end }}
+</ul>`), nil)
+
+	views := blocks.New(mfs).Coverage(true)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := views.TemplateString("index", "main", map[string]any{"User": "kataras", "Items": []string{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<html>
+<script>var user = "kataras";</script>
+
+	<p>Hello, kataras</p>
+
+<ul>
+
+	<li>a</li>
+
+	<li>b</li>
+
+</ul>
+</html>`
+	if got != expected {
+		t.Fatalf("instrumentation changed the output, expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	report := views.CoverageReport()
+	if len(report.Files) != 2 {
+		t.Fatalf("expected coverage of 2 files but got: %d", len(report.Files))
+	}
+
+	index := report.Files[0]
+	if index.Filename != "index.html" {
+		t.Fatalf("expected index.html but got: %s", index.Filename)
+	}
+
+	expectedLines := map[int]uint64{1: 1, 2: 1, 4: 0, 6: 0, 8: 1, 9: 1, 10: 2, 12: 0, 14: 1}
+	for line, count := range expectedLines {
+		if got, ok := index.Lines[line]; !ok || got != count {
+			t.Errorf("expected line %d to be executed %d times but got: %d (tracked: %v)", line, count, got, ok)
+		}
+	}
+
+	var text strings.Builder
+	if err = report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "index.html:3: if block not covered") {
+		t.Fatalf("expected the else if block to be reported as not covered but got:\n%s", text.String())
+	}
+
+	var html strings.Builder
+	if err = report.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), `<tr class="uncovered"><td class="num">6</td>`) {
+		t.Fatalf("expected line 6 to be highlighted as uncovered:\n%s", html.String())
+	}
+}
@@ -0,0 +1,138 @@
+package blocks
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// SharedData adds the elements of "data" to the engine's shared data,
+// e.g. the site name, the build version, feature flags or navigation items.
+// Shared data are merged into the data of every `ExecuteTemplate`,
+// `ExecuteTemplateContext` and `TemplateString` call.
+// Per-request shared data can be attached to a request's context through
+// the `SetData` middleware or the `WithData` function.
+//
+// Precedence, from highest to lowest:
+//
+//  1. the data passed to the `ExecuteTemplate` call
+//  2. the request's shared data (the inner `WithData` call wins)
+//  3. the engine's shared data
+//
+// When the data is a map with string keys (or nil) a new map is rendered
+// with all the above merged, the caller's map is never modified.
+// Any other data type (e.g. a struct) is rendered as it is and the shared data
+// are accessible through the {{ shared "Key" }} template function,
+// which is available on both cases, following the same precedence (except the first).
+//
+// It must be called before the engine is loaded.
+// Calling it with an empty map enables the "shared" template function.
+func (v *Blocks) SharedData(data map[string]any) *Blocks {
+	if v.sharedData == nil {
+		v.sharedData = make(map[string]any, len(data))
+		v.ContextFuncs(map[string]any{
+			"shared": v.shared,
+		})
+	}
+
+	for key, value := range data {
+		v.sharedData[key] = value
+	}
+
+	return v
+}
+
+// shared is the "shared" template function.
+func (v *Blocks) shared(ctx context.Context, key string) any {
+	if value, ok := DataFromContext(ctx)[key]; ok {
+		return value
+	}
+
+	return v.sharedData[key]
+}
+
+// mergeData merges the engine's and the "ctx" shared data with the "data",
+// see `SharedData`.
+func (v *Blocks) mergeData(ctx context.Context, data any) any {
+	requestData := DataFromContext(ctx)
+	if len(v.sharedData) == 0 && len(requestData) == 0 {
+		return data
+	}
+
+	if data != nil {
+		val := reflect.ValueOf(data)
+		if val.Kind() != reflect.Map || val.Type().Key().Kind() != reflect.String {
+			return data
+		}
+	}
+
+	merged := make(map[string]any, len(v.sharedData)+len(requestData))
+	for key, value := range v.sharedData {
+		merged[key] = value
+	}
+
+	for key, value := range requestData {
+		merged[key] = value
+	}
+
+	switch m := data.(type) {
+	case nil:
+	case map[string]any:
+		for key, value := range m {
+			merged[key] = value
+		}
+	default:
+		iter := reflect.ValueOf(data).MapRange()
+		for iter.Next() {
+			merged[iter.Key().String()] = iter.Value().Interface()
+		}
+	}
+
+	return merged
+}
+
+type dataContextKey struct{}
+
+// WithData returns a copy of "ctx" which holds the "data"
+// merged with any previous shared data of the "ctx".
+// The keys of the new "data" override the existing ones.
+// See `SharedData` and `SetData` too.
+func WithData(ctx context.Context, data map[string]any) context.Context {
+	parent := DataFromContext(ctx)
+
+	merged := make(map[string]any, len(parent)+len(data))
+	for key, value := range parent {
+		merged[key] = value
+	}
+
+	for key, value := range data {
+		merged[key] = value
+	}
+
+	return context.WithValue(ctx, dataContextKey{}, merged)
+}
+
+// DataFromContext returns the shared data stored in "ctx" through `WithData` or `SetData`.
+// The returned map should be treated as read-only.
+func DataFromContext(ctx context.Context) map[string]any {
+	if ctx == nil {
+		return nil
+	}
+
+	data, _ := ctx.Value(dataContextKey{}).(map[string]any)
+	return data
+}
+
+// SetData returns a handler wrapper which attaches the "data"
+// to the request's context as shared data, so they are
+// merged into the data of the `ExecuteTemplateContext` calls
+// which use that request's context.
+// See `SharedData` and `Set` too.
+func SetData(data map[string]any) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(WithData(r.Context(), data))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
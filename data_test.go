@@ -0,0 +1,69 @@
+package blocks_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestSharedData(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("map.html", []byte(`{{ .Site }}|{{ .Version }}|{{ .Title }}`), nil)
+	mfs.ParseTemplate("struct.html", []byte(`{{ shared "Site" }}|{{ shared "Version" }}|{{ .Title }}`), nil)
+
+	views := blocks.New(mfs).SharedData(map[string]any{
+		"Site":    "Blocks",
+		"Version": "1.0.0",
+		"Title":   "Default Title",
+	})
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data := map[string]any{"Title": "Map Title"}
+		if err := views.ExecuteTemplateContext(r.Context(), w, "map", "", data); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("\n"))
+
+		if len(data) != 1 {
+			t.Fatalf("the caller's data were modified: %v", data)
+		}
+
+		structData := struct{ Title string }{"Struct Title"}
+		if err := views.ExecuteTemplateContext(r.Context(), w, "struct", "", structData); err != nil {
+			t.Fatal(err)
+		}
+	})
+	handler = blocks.SetData(map[string]any{"Version": "2.0.0"})(handler)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	expected := "Blocks|2.0.0|Map Title\nBlocks|2.0.0|Struct Title"
+	if got := rec.Body.String(); got != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	got, err := views.TemplateString("map", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Blocks|1.0.0|Default Title"; got != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	ctx := blocks.WithData(blocks.WithData(context.Background(), map[string]any{"Site": "Outer"}), map[string]any{"Title": "Inner"})
+	var b strings.Builder
+	if err = views.ExecuteTemplateContext(ctx, &b, "map", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Outer|1.0.0|Inner"; b.String() != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, b.String())
+	}
+}
@@ -0,0 +1,136 @@
+package blocks
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template/parse"
+)
+
+// fileDelims are the action delimiters of the files matching the pattern, see `DelimsFor`.
+type fileDelims struct {
+	pattern     string
+	left, right string
+}
+
+// DelimsFor sets the action delimiters of the template files which match the "pattern",
+// e.g. for views which embed Vue or Alpine markup that uses "{{ }}" too.
+// The "pattern" is a path.Match pattern of the file name, relative to the root directory,
+// e.g. "components/*.html", or of its base name if it has no slash, e.g. "*.vue.html".
+// A pattern which ends with a slash, e.g. "components/", matches the whole directory.
+// When more than one pattern match a file the last one wins.
+//
+// A template file can declare its delimiters on its front matter too,
+// which takes precedence, e.g.
+//
+//	---
+//	delims: "[[ ]]"
+//	---
+//
+// The rest of the files use the `Delims` ones.
+// It should be called before the engine is loaded.
+func (v *Blocks) DelimsFor(pattern, left, right string) *Blocks {
+	left, right = defaultDelims(left, right)
+	v.fileDelims = append(v.fileDelims, fileDelims{pattern: pattern, left: left, right: right})
+	return v
+}
+
+// delimsOf returns the action delimiters of the "filename" file with the "frontMatter".
+func (v *Blocks) delimsOf(filename string, frontMatter map[string]any) (string, string, error) {
+	left, right := v.left, v.right
+	for _, d := range v.fileDelims {
+		if matchFilePattern(d.pattern, filename) {
+			left, right = d.left, d.right
+		}
+	}
+
+	value, ok := frontMatter["delims"]
+	if !ok {
+		return left, right, nil
+	}
+
+	var delims []string
+	switch value := value.(type) {
+	case string: // delims: "[[ ]]"
+		delims = strings.Fields(value)
+	case []any: // delims: ["[[", "]]"]
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				delims = append(delims, s)
+			}
+		}
+	}
+
+	if len(delims) != 2 {
+		return "", "", fmt.Errorf("%s: front matter: delims: expected a left and a right delimiter but got %v", filename, value)
+	}
+
+	left, right = defaultDelims(delims[0], delims[1])
+	return left, right, nil
+}
+
+// defaultDelims returns the "left" and "right" delimiters,
+// an empty one stands for the corresponding default: {{ or }}.
+func defaultDelims(left, right string) (string, string) {
+	if left == "" {
+		left = "{{"
+	}
+	if right == "" {
+		right = "}}"
+	}
+
+	return left, right
+}
+
+func matchFilePattern(pattern, filename string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(filename, pattern)
+	}
+
+	if !strings.Contains(pattern, "/") {
+		filename = path.Base(filename)
+	}
+
+	matched, _ := path.Match(pattern, filename)
+	return matched
+}
+
+// delimsRegexps are the delimiters-aware regular expressions of the layouts.
+type delimsRegexps struct {
+	// yield matches any {{ yield . }} or similar patterns.
+	yield *regexp.Regexp
+	// yieldReplacement replaces the yield matches with {{ template "content" . }}.
+	yieldReplacement string
+	// layout matches various forms of {{ template "content" ... }} and {{ yield ... }}.
+	layout *regexp.Regexp
+}
+
+var delimsRegexpsCache sync.Map // "left right" -> *delimsRegexps.
+
+// regexpsOf returns the regular expressions of the "left" and "right" delimiters.
+func regexpsOf(left, right string) *delimsRegexps {
+	left, right = defaultDelims(left, right)
+	key := left + " " + right
+	if cached, ok := delimsRegexpsCache.Load(key); ok {
+		return cached.(*delimsRegexps)
+	}
+
+	l, r := regexp.QuoteMeta(left), regexp.QuoteMeta(right)
+	// notRight matches anything but the first character of the right delimiter.
+	notRight := `[^` + regexp.QuoteMeta(right[:1]) + `]*`
+
+	re := &delimsRegexps{
+		yield:            regexp.MustCompile(l + `-?\s*yield\s*(.*?)\s*-?` + r),
+		yieldReplacement: left + ` template "content" $1 ` + right,
+		layout:           regexp.MustCompile(l + `-?\s*(template\s*"content"\s*` + notRight + `|yield\s*` + notRight + `)\s*-?` + r),
+	}
+	cached, _ := delimsRegexpsCache.LoadOrStore(key, re)
+	return cached.(*delimsRegexps)
+}
+
+// parseTrees parses the file's contents with its delimiters, see `parseTrees`.
+func (f *templateFile) parseTrees() (map[string]*parse.Tree, error) {
+	return parseTrees(f.filename, f.contents, f.left, f.right)
+}
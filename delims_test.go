@@ -0,0 +1,72 @@
+package blocks_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestDelimsFor(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<main>{{ .Title }}{{ yield . }}</main>`), nil)
+	mfs.ParseTemplate("layouts/app.vue.html", []byte(`<div id="app">{{ message }}[[ .Title ]][[- yield . -]]</div>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>{{ .Title }}</h1>{{ partial "vue/counter" . }}`), nil)
+	mfs.ParseTemplate("vue/counter.html", []byte(`<button @click="count++">{{ count }}</button>[[ .Title ]]`), nil)
+	mfs.ParseTemplate("alpine.html", []byte(`---
+delims: "<% %>"
+---
+<span x-text="{{ open }}"><% .Title %></span>`), nil)
+
+	views := blocks.New(mfs).DelimsFor("vue/", "[[", "]]").DelimsFor("*.vue.html", "[[", "]]")
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tmpl, layout string
+		expected     string
+	}{
+		{"index", "", `<h1>Home</h1><button @click="count++">{{ count }}</button>Home`},
+		{"vue/counter", "", `<button @click="count++">{{ count }}</button>Home`},
+		{"alpine", "", `<span x-text="{{ open }}">Home</span>`},
+		{"alpine", "main", `<main>Home<span x-text="{{ open }}">Home</span></main>`},
+		{"index", "app.vue", `<div id="app">{{ message }}Home<h1>Home</h1><button @click="count++">{{ count }}</button>Home</div>`},
+	}
+
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := views.ExecuteTemplate(&b, tt.tmpl, tt.layout, map[string]any{"Title": "Home"}); err != nil {
+			t.Fatalf("%s/%s: %v", tt.layout, tt.tmpl, err)
+		}
+
+		if got := b.String(); got != tt.expected {
+			t.Fatalf("%s/%s: expected:\n%s\nbut got:\n%s", tt.layout, tt.tmpl, tt.expected, got)
+		}
+	}
+}
+
+func TestDelimsEmpty(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<main>{{ yield . }}</main>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>{{ . }}</h1>`), nil)
+	mfs.ParseTemplate("vue/counter.html", []byte(`<b>{{ . }}</b>`), nil)
+
+	views := blocks.New(mfs).Delims("", "").DelimsFor("vue/", "", "")
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	for tmpl, expected := range map[string]string{
+		"index":       "<main><h1>Home</h1></main>",
+		"vue/counter": "<main><b>Home</b></main>",
+	} {
+		contents, err := views.TemplateString(tmpl, "main", "Home")
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl, err)
+		}
+		if contents != expected {
+			t.Fatalf("%s: expected:\n%s\nbut got:\n%s", tmpl, expected, contents)
+		}
+	}
+}
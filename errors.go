@@ -0,0 +1,176 @@
+package blocks
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of "did you mean" suggestions of the not found errors.
+const maxSuggestions = 3
+
+// TemplateNotExistError reports that a content template was not found.
+// It unwraps to `ErrNotExist`, so errors.Is(err, fs.ErrNotExist) reports true.
+type TemplateNotExistError struct {
+	Name string
+	// Key is the lookup key of the missing template,
+	// the template name or the layout and template pair one, see `Layouts`.
+	Key string
+	// Suggestions are the names of the existing templates closest to the Name.
+	Suggestions []string
+}
+
+// Error implements the `error` interface.
+func (e *TemplateNotExistError) Error() string {
+	return fmt.Sprintf("template '%s' does not exist%s", e.Name, didYouMean(e.Suggestions))
+}
+
+// Unwrap returns the `ErrNotExist` of the template.
+func (e *TemplateNotExistError) Unwrap() error {
+	return ErrNotExist{e.Name}
+}
+
+// LayoutNotExistError reports that a layout was not found.
+// It unwraps to `ErrNotExist`, so errors.Is(err, fs.ErrNotExist) reports true.
+type LayoutNotExistError struct {
+	Name string
+	// Template is the content template rendered with the missing layout.
+	Template string
+	// Key is the lookup key of the layout and template pair, see `Layouts`.
+	Key string
+	// Suggestions are the names of the existing layouts closest to the Name.
+	Suggestions []string
+}
+
+// Error implements the `error` interface.
+func (e *LayoutNotExistError) Error() string {
+	return fmt.Sprintf("layout '%s' does not exist%s", e.Name, didYouMean(e.Suggestions))
+}
+
+// Unwrap returns the `ErrNotExist` of the layout.
+func (e *LayoutNotExistError) Unwrap() error {
+	return ErrNotExist{e.Name}
+}
+
+// LayoutTemplateNotExistError reports that both the layout and the content template exist
+// but they were not loaded as a pair, e.g. when the lookup happens while the templates are reloaded.
+// It unwraps to `ErrNotExist`, so errors.Is(err, fs.ErrNotExist) reports true.
+type LayoutTemplateNotExistError struct {
+	Template, Layout string
+	// Key is the lookup key of the pair, see `Layouts`.
+	Key string
+}
+
+// Error implements the `error` interface.
+func (e *LayoutTemplateNotExistError) Error() string {
+	return fmt.Sprintf("template '%s' with layout '%s' does not exist (lookup key '%s')", e.Template, e.Layout, e.Key)
+}
+
+// Unwrap returns the `ErrNotExist` of the layout.
+func (e *LayoutTemplateNotExistError) Unwrap() error {
+	return ErrNotExist{e.Layout}
+}
+
+// Is reports whether the "target" is fs.ErrNotExist, so
+// errors.Is(err, fs.ErrNotExist) can be used to check for any of the not found errors.
+func (e ErrNotExist) Is(target error) bool {
+	return target == fs.ErrNotExist
+}
+
+// notExistError returns the not found error of the "tmplName" template
+// rendered with the "layoutName" layout, if not empty.
+func (v *Blocks) notExistError(tmplName, layoutName string) error {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	key := tmplName
+	if layoutName != "" {
+		key = makeLayoutTemplateName(tmplName, layoutName)
+	}
+
+	if _, ok := v.Templates[tmplName]; !ok {
+		names := make([]string, 0, len(v.Templates))
+		for name := range v.Templates {
+			names = append(names, name)
+		}
+
+		return &TemplateNotExistError{Name: tmplName, Key: key, Suggestions: suggest(tmplName, names)}
+	}
+
+	if layoutName == "" {
+		return &TemplateNotExistError{Name: tmplName, Key: key}
+	}
+
+	layouts := templateFileNames(v.files, true)
+	for _, name := range layouts {
+		if name == layoutName {
+			return &LayoutTemplateNotExistError{Template: tmplName, Layout: layoutName, Key: key}
+		}
+	}
+
+	return &LayoutNotExistError{Name: layoutName, Template: tmplName, Key: key, Suggestions: suggest(layoutName, layouts)}
+}
+
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (did you mean '%s'?)", strings.Join(suggestions, "', '"))
+}
+
+// suggest returns the "names" closest to the "name", the closest first.
+// A name is close when its edit distance is at most a third of the name's length, and at least 2.
+func suggest(name string, names []string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	limit := max(len(name)/3, 2)
+	var candidates []candidate
+	for _, n := range names {
+		if d := levenshtein(strings.ToLower(name), strings.ToLower(n)); d <= limit {
+			candidates = append(candidates, candidate{name: n, distance: d})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance == candidates[j].distance {
+			return candidates[i].name < candidates[j].name
+		}
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	suggestions := make([]string, 0, min(len(candidates), maxSuggestions))
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+
+	return suggestions
+}
+
+// levenshtein returns the edit distance of "a" and "b".
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,73 @@
+package blocks_test
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestNotExistErrors(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<main>{{ yield . }}</main>`), nil)
+	mfs.ParseTemplate("layouts/admin.html", []byte(`<admin>{{ yield . }}</admin>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>Index</h1>`), nil)
+	mfs.ParseTemplate("users/index.html", []byte(`<h1>Users</h1>`), nil)
+
+	views := blocks.New(mfs)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	render := func(tmpl, layout string) error {
+		return views.ExecuteTemplate(new(strings.Builder), tmpl, layout, nil)
+	}
+
+	err := render("indx", "main")
+	var tmplErr *blocks.TemplateNotExistError
+	if !errors.As(err, &tmplErr) {
+		t.Fatalf("expected a template error but got %T: %v", err, err)
+	}
+	if expected := (&blocks.TemplateNotExistError{Name: "indx", Key: "mainindx", Suggestions: []string{"index"}}); !reflect.DeepEqual(tmplErr, expected) {
+		t.Fatalf("expected:\n%#v\nbut got:\n%#v", expected, tmplErr)
+	}
+	if expected := "template 'indx' does not exist (did you mean 'index'?)"; err.Error() != expected {
+		t.Fatalf("expected message:\n%s\nbut got:\n%s", expected, err.Error())
+	}
+
+	err = render("index", "mian")
+	var layoutErr *blocks.LayoutNotExistError
+	if !errors.As(err, &layoutErr) {
+		t.Fatalf("expected a layout error but got %T: %v", err, err)
+	}
+	if expected := (&blocks.LayoutNotExistError{Name: "mian", Template: "index", Key: "mianindex", Suggestions: []string{"main"}}); !reflect.DeepEqual(layoutErr, expected) {
+		t.Fatalf("expected:\n%#v\nbut got:\n%#v", expected, layoutErr)
+	}
+
+	err = render("unknown", "")
+	if !errors.As(err, &tmplErr) || len(tmplErr.Suggestions) != 0 {
+		t.Fatalf("expected a template error without suggestions but got %T: %v", err, err)
+	}
+
+	// A missing pair, e.g. a layout added after the load.
+	delete(views.Layouts, "mainindex")
+	err = render("index", "main")
+	var pairErr *blocks.LayoutTemplateNotExistError
+	if !errors.As(err, &pairErr) || pairErr.Key != "mainindex" {
+		t.Fatalf("expected a layout and template pair error but got %T: %v", err, err)
+	}
+
+	for _, err := range []error{tmplErr, layoutErr, pairErr} {
+		if !errors.Is(err, fs.ErrNotExist) || !errors.As(err, new(blocks.ErrNotExist)) {
+			t.Fatalf("expected %T to be a fs.ErrNotExist and a blocks.ErrNotExist", err)
+		}
+	}
+
+	// The old value is matched through errors.Is, not ==.
+	if err = render("unknown", ""); !errors.Is(err, blocks.ErrNotExist{Name: "unknown"}) || errors.Is(err, blocks.ErrNotExist{Name: "index"}) {
+		t.Fatalf("expected the error to match the ErrNotExist of its name but got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,310 @@
+package blocks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+)
+
+// DefaultTranslateFunc is the name of the translation function
+// `ExtractMessages` looks for when no function names are given.
+const DefaultTranslateFunc = "tr"
+
+// Message is a translatable string extracted from the templates.
+type Message struct {
+	// Key is the literal argument given to the translation function.
+	Key string
+	// References holds the "file:line" positions the key is used at.
+	References []string
+}
+
+// ExtractMessages walks the engine's template files through the same
+// read and transform pipeline `Load` does and returns the
+// literal (string) arguments of the translation function(s) "funcNames",
+// e.g. {{ tr "Hello" }} or {{ "Hello" | tr }}.
+// If "funcNames" is empty then the `DefaultTranslateFunc` is used.
+//
+// The returned messages are sorted by their key.
+// It does not require a previous `Load` call.
+func (v *Blocks) ExtractMessages(ctx context.Context, funcNames ...string) ([]Message, error) {
+	if len(funcNames) == 0 {
+		funcNames = []string{DefaultTranslateFunc}
+	}
+
+	files, err := v.readTemplateFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string][]string)
+	for _, file := range files {
+		trees, err := file.parseTrees()
+		if err != nil {
+			return nil, mapError(err, files)
+		}
+
+		for _, tree := range trees {
+			walkNodes(tree.Root, func(node parse.Node) {
+				pipe, ok := node.(*parse.PipeNode)
+				if !ok {
+					return
+				}
+
+				for i, cmd := range pipe.Cmds {
+					key, ok := translationKey(pipe, i, cmd, funcNames)
+					if !ok {
+						continue
+					}
+
+					line, _ := file.position(int(cmd.Position()))
+					refs[key] = appendUnique(refs[key], fmt.Sprintf("%s:%d", file.filename, line))
+				}
+			})
+		}
+	}
+
+	messages := make([]Message, 0, len(refs))
+	for key, references := range refs {
+		sortReferences(references)
+		messages = append(messages, Message{Key: key, References: references})
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Key < messages[j].Key
+	})
+
+	return messages, nil
+}
+
+// translationKey reports the literal key of a translation function's call.
+func translationKey(pipe *parse.PipeNode, i int, cmd *parse.CommandNode, funcNames []string) (string, bool) {
+	if len(cmd.Args) == 0 {
+		return "", false
+	}
+
+	ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok || !containsString(funcNames, ident.Ident) {
+		return "", false
+	}
+
+	if len(cmd.Args) > 1 { // {{ tr "key" }}
+		if str, ok := cmd.Args[1].(*parse.StringNode); ok {
+			return str.Text, true
+		}
+
+		return "", false
+	}
+
+	if i > 0 { // {{ "key" | tr }}
+		if prev := pipe.Cmds[i-1]; len(prev.Args) == 1 {
+			if str, ok := prev.Args[0].(*parse.StringNode); ok {
+				return str.Text, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// parseTrees parses the "contents" without checking for function existence,
+// so templates can be inspected without the engine's funcs.
+func parseTrees(name, contents, left, right string) (map[string]*parse.Tree, error) {
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	treeSet := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(contents, left, right, treeSet); err != nil {
+		return nil, err
+	}
+
+	if _, exists := treeSet[name]; !exists && tree.Root != nil {
+		treeSet[name] = tree
+	}
+
+	return treeSet, nil
+}
+
+// walkNodes calls "fn" for the "node" and all of its descendants.
+func walkNodes(node parse.Node, fn func(parse.Node)) {
+	if node == nil {
+		return
+	}
+
+	fn(node)
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkNodes(child, fn)
+		}
+	case *parse.ActionNode:
+		walkNodes(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkNodes(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkNodes(arg, fn)
+		}
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkNodes(n.Pipe, fn)
+	case *parse.ChainNode:
+		walkNodes(n.Node, fn)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, fn func(parse.Node)) {
+	walkNodes(n.Pipe, fn)
+	if n.List != nil {
+		walkNodes(n.List, fn)
+	}
+	if n.ElseList != nil {
+		walkNodes(n.ElseList, fn)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortReferences sorts the "file:line" references by their file name and then by their line number,
+// e.g. "a.html:9" before "a.html:10".
+func sortReferences(references []string) {
+	split := func(ref string) (string, int) {
+		i := strings.LastIndexByte(ref, ':')
+		line, _ := strconv.Atoi(ref[i+1:])
+		return ref[:i], line
+	}
+
+	sort.Slice(references, func(i, j int) bool {
+		fileI, lineI := split(references[i])
+		fileJ, lineJ := split(references[j])
+		if fileI != fileJ {
+			return fileI < fileJ
+		}
+
+		return lineI < lineJ
+	})
+}
+
+func appendUnique(list []string, s string) []string {
+	if containsString(list, s) {
+		return list
+	}
+
+	return append(list, s)
+}
+
+// CatalogEntry is a single translation entry of a `Catalog`.
+type CatalogEntry struct {
+	Translation string   `json:"translation"`
+	References  []string `json:"references,omitempty"`
+	// Obsolete reports whether the key is no longer used by any template.
+	Obsolete bool `json:"obsolete,omitempty"`
+}
+
+// Catalog is a locale's translations, keyed by the message key.
+// It is stored as JSON, see `ReadCatalog` and `Catalog.WriteTo`.
+type Catalog map[string]*CatalogEntry
+
+// ReadCatalog decodes a JSON catalog from "r".
+func ReadCatalog(r io.Reader) (Catalog, error) {
+	catalog := make(Catalog)
+	if err := json.NewDecoder(r).Decode(&catalog); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("catalog: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// WriteTo encodes the catalog as indented JSON to "w".
+// It implements the `io.WriterTo` interface.
+func (c Catalog) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
+
+// MergeResult reports the changes of a `Catalog.Merge` call.
+type MergeResult struct {
+	// Added keys are new messages, they have no translation yet.
+	Added []string
+	// Obsolete keys exist in the catalog but are no longer used by any template.
+	Obsolete []string
+	// Untranslated keys are used by templates but have an empty translation.
+	Untranslated []string
+}
+
+// Merge synchronizes the catalog with the extracted "messages".
+// New keys are added with an empty translation, references are refreshed
+// and keys which are no longer used are flagged as obsolete (not removed),
+// so existing translations are never lost.
+func (c Catalog) Merge(messages []Message) MergeResult {
+	var result MergeResult
+
+	used := make(map[string]struct{}, len(messages))
+	for _, msg := range messages {
+		used[msg.Key] = struct{}{}
+
+		entry := c[msg.Key]
+		if entry == nil { // missing or a null one, e.g. {"key": null}.
+			entry = new(CatalogEntry)
+			c[msg.Key] = entry
+			result.Added = append(result.Added, msg.Key)
+		}
+
+		entry.References = msg.References
+		entry.Obsolete = false
+
+		if entry.Translation == "" {
+			result.Untranslated = append(result.Untranslated, msg.Key)
+		}
+	}
+
+	for key, entry := range c {
+		if _, ok := used[key]; ok {
+			continue
+		}
+
+		if entry == nil {
+			entry = new(CatalogEntry)
+			c[key] = entry
+		}
+
+		entry.Obsolete = true
+		entry.References = nil
+		result.Obsolete = append(result.Obsolete, key)
+	}
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Obsolete)
+	sort.Strings(result.Untranslated)
+	return result
+}
@@ -0,0 +1,74 @@
+package blocks_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestExtractMessages(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<title>{{ tr "Site" }}</title>
+{{ yield . }}`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>{{ tr "Hello" }}</h1>
+<p>{{ "Welcome" | tr }}</p>
+{{ if .User }}{{ tr "Hello" }}{{ end }}
+{{ tr .Dynamic }}
+
+
+
+
+
+<footer>{{ tr "Hello" }}</footer>`), nil)
+
+	messages, err := blocks.New(mfs).ExtractMessages(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []blocks.Message{
+		{Key: "Hello", References: []string{"index.html:1", "index.html:3", "index.html:10"}},
+		{Key: "Site", References: []string{"layouts/main.html:1"}},
+		{Key: "Welcome", References: []string{"index.html:2"}},
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("expected:\n%#+v\nbut got:\n%#+v", expected, messages)
+	}
+
+	catalog := blocks.Catalog{
+		"Hello":   {Translation: "Γεια"},
+		"Removed": {Translation: "Αφαιρέθηκε"},
+	}
+	result := catalog.Merge(messages)
+
+	if expected := []string{"Site", "Welcome"}; !reflect.DeepEqual(result.Added, expected) {
+		t.Fatalf("expected added keys: %v but got: %v", expected, result.Added)
+	}
+	if expected := []string{"Removed"}; !reflect.DeepEqual(result.Obsolete, expected) || !catalog["Removed"].Obsolete {
+		t.Fatalf("expected obsolete keys: %v but got: %v", expected, result.Obsolete)
+	}
+	if expected := []string{"Site", "Welcome"}; !reflect.DeepEqual(result.Untranslated, expected) {
+		t.Fatalf("expected untranslated keys: %v but got: %v", expected, result.Untranslated)
+	}
+	if got := catalog["Hello"].Translation; got != "Γεια" {
+		t.Fatalf("expected existing translation to be kept but got: %q", got)
+	}
+}
+
+func TestCatalogNullEntries(t *testing.T) {
+	catalog, err := blocks.ReadCatalog(strings.NewReader(`{"Hello": null, "Removed": null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := catalog.Merge([]blocks.Message{{Key: "Hello", References: []string{"index.html:1"}}})
+	if !reflect.DeepEqual(result.Added, []string{"Hello"}) || !reflect.DeepEqual(result.Obsolete, []string{"Removed"}) {
+		t.Fatalf("expected the null entries to be missing ones but got: %#v", result)
+	}
+	if catalog["Hello"] == nil || catalog["Removed"] == nil || !catalog["Removed"].Obsolete {
+		t.Fatalf("expected the null entries to be replaced but got: %#v", catalog)
+	}
+}
@@ -0,0 +1,364 @@
+package blocks
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Feed is an RSS 2.0 or Atom feed of a site, see `RSS` and `Atom`.
+//
+// Usage:
+//
+//	feed := &blocks.Feed{
+//	  Title:   "Blog",
+//	  BaseURL: "https://example.com",
+//	  Path:    "/feed.xml",
+//	  Items:   posts.FeedItems(func(e *blocks.Entry) string { return "/posts/" + e.Slug + "/" }),
+//	}
+//	site.File(feed.Path, feed.RSS)
+//	// or serve it:
+//	http.Handle("/feed.xml", feed.RSSHandler())
+type Feed struct {
+	Title, Description, Author string
+	// BaseURL is the absolute URL of the site the paths are relative to, e.g. "https://example.com".
+	BaseURL string
+	// Path is the path of the feed itself, e.g. "/feed.xml".
+	Path string
+	// Updated is the last modification time of the feed, defaults to the newest item's date
+	// or, on an Atom feed without dates, the current time.
+	Updated time.Time
+	Items   []FeedItem
+}
+
+// FeedItem is an item of a `Feed`.
+type FeedItem struct {
+	Title string
+	// Path is the path of the item's page, relative to the feed's BaseURL.
+	Path string
+	// Summary is the HTML description of the item.
+	Summary string
+	// Date is the publication date of the item and Updated its last modification one, if any.
+	Date, Updated time.Time
+}
+
+// FeedItems returns the feed items of the entries, in their order.
+// The "entryPath" returns the path of an entry's page, e.g. "/posts/" + entry.Slug + "/".
+// The "lastmod" front matter date, if any, is the item's Updated time.
+func (c Collection) FeedItems(entryPath func(*Entry) string) []FeedItem {
+	items := make([]FeedItem, 0, len(c))
+	for _, entry := range c {
+		items = append(items, FeedItem{
+			Title:   entry.Title,
+			Path:    entryPath(entry),
+			Summary: string(entry.Summary),
+			Date:    entry.Date,
+			Updated: entry.LastMod(),
+		})
+	}
+
+	return items
+}
+
+// LastMod returns the "lastmod" (or "updated") front matter date of the entry, its Date otherwise.
+func (e *Entry) LastMod() time.Time {
+	return lastMod(e.FrontMatter, e.Date)
+}
+
+// lastMod returns the "lastmod", "updated" or "date" date of the "frontMatter", the "fallback" otherwise.
+func lastMod(frontMatter map[string]any, fallback time.Time) time.Time {
+	for _, key := range []string{"lastmod", "updated", "date"} {
+		if t, ok := frontMatter[key].(time.Time); ok {
+			return t
+		}
+	}
+
+	return fallback
+}
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomXMLNS string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          *atomLink `xml:"atom:link,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate,omitempty"`
+	Description string `xml:"description,omitempty"`
+}
+
+// RSS returns the RSS 2.0 document of the feed.
+func (f *Feed) RSS() ([]byte, error) {
+	doc := rssFeed{
+		Version:   "2.0",
+		AtomXMLNS: "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        absoluteURL(f.BaseURL, "/"),
+			Description: f.Description,
+		},
+	}
+	if f.Path != "" {
+		doc.Channel.Self = &atomLink{Href: absoluteURL(f.BaseURL, f.Path), Rel: "self", Type: "application/rss+xml"}
+	}
+	if updated := f.updated(); !updated.IsZero() {
+		doc.Channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+
+	for _, item := range f.Items {
+		link := absoluteURL(f.BaseURL, item.Path)
+		rssItem := rssItem{Title: item.Title, Link: link, GUID: link, Description: item.Summary}
+		if !item.Date.IsZero() {
+			rssItem.PubDate = item.Date.Format(time.RFC1123Z)
+		}
+		doc.Channel.Items = append(doc.Channel.Items, rssItem)
+	}
+
+	return marshalXML(doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Links   []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title     string       `xml:"title"`
+	ID        string       `xml:"id"`
+	Link      atomLink     `xml:"link"`
+	Published string       `xml:"published,omitempty"`
+	Updated   string       `xml:"updated"`
+	Summary   *atomSummary `xml:"summary,omitempty"`
+}
+
+type atomSummary struct {
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// Atom returns the Atom document of the feed.
+func (f *Feed) Atom() ([]byte, error) {
+	updated := f.updated()
+	if updated.IsZero() {
+		updated = time.Now() // the updated element is required.
+	}
+	doc := atomFeed{
+		Title:   f.Title,
+		ID:      absoluteURL(f.BaseURL, "/"),
+		Links:   []atomLink{{Href: absoluteURL(f.BaseURL, "/")}},
+		Updated: updated.Format(time.RFC3339),
+	}
+	if f.Path != "" {
+		doc.Links = append(doc.Links, atomLink{Href: absoluteURL(f.BaseURL, f.Path), Rel: "self", Type: "application/atom+xml"})
+	}
+	if f.Author != "" {
+		doc.Author = &atomAuthor{Name: f.Author}
+	}
+
+	for _, item := range f.Items {
+		link := absoluteURL(f.BaseURL, item.Path)
+		entry := atomEntry{Title: item.Title, ID: link, Link: atomLink{Href: link}}
+		if !item.Date.IsZero() {
+			entry.Published = item.Date.Format(time.RFC3339)
+		}
+
+		itemUpdated := item.Updated
+		if itemUpdated.IsZero() {
+			itemUpdated = item.Date
+		}
+		if itemUpdated.IsZero() {
+			itemUpdated = updated
+		}
+		entry.Updated = itemUpdated.Format(time.RFC3339)
+
+		if item.Summary != "" {
+			entry.Summary = &atomSummary{Type: "html", Content: item.Summary}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshalXML(doc)
+}
+
+// updated returns the feed's Updated time or the newest date of its items.
+func (f *Feed) updated() time.Time {
+	updated := f.Updated
+	if !updated.IsZero() {
+		return updated
+	}
+
+	for _, item := range f.Items {
+		for _, t := range []time.Time{item.Date, item.Updated} {
+			if t.After(updated) {
+				updated = t
+			}
+		}
+	}
+
+	return updated
+}
+
+// RSSHandler returns an http.Handler which serves the RSS document of the feed.
+func (f *Feed) RSSHandler() http.Handler {
+	return xmlHandler("application/rss+xml; charset=utf-8", f.RSS)
+}
+
+// AtomHandler returns an http.Handler which serves the Atom document of the feed.
+func (f *Feed) AtomHandler() http.Handler {
+	return xmlHandler("application/atom+xml; charset=utf-8", f.Atom)
+}
+
+// Sitemap is a sitemap.xml document, see `Site.Sitemap`.
+type Sitemap struct {
+	// BaseURL is the absolute URL of the site the paths are relative to, e.g. "https://example.com".
+	BaseURL string
+	URLs    []SitemapURL
+}
+
+// SitemapURL is a page of a `Sitemap`.
+type SitemapURL struct {
+	Path string
+	// LastMod is the last modification time of the page, if known.
+	LastMod time.Time
+}
+
+// SitemapURLs returns the sitemap URLs of the entries, see `FeedItems`.
+func (c Collection) SitemapURLs(entryPath func(*Entry) string) []SitemapURL {
+	urls := make([]SitemapURL, 0, len(c))
+	for _, entry := range c {
+		urls = append(urls, SitemapURL{Path: entryPath(entry), LastMod: entry.LastMod()})
+	}
+
+	return urls
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// XML returns the sitemap.xml document.
+func (s *Sitemap) XML() ([]byte, error) {
+	var doc sitemapURLSet
+	for _, u := range s.URLs {
+		url := sitemapURL{Loc: absoluteURL(s.BaseURL, u.Path)}
+		if !u.LastMod.IsZero() {
+			url.LastMod = u.LastMod.Format(time.RFC3339)
+		}
+		doc.URLs = append(doc.URLs, url)
+	}
+
+	return marshalXML(doc)
+}
+
+// ServeHTTP serves the sitemap.xml document.
+func (s *Sitemap) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	xmlHandler("application/xml; charset=utf-8", s.XML).ServeHTTP(w, r)
+}
+
+// Sitemap returns the sitemap of the site's HTML pages, except the NotFound one.
+// The last modification time of a page is its route's LastMod or
+// the "lastmod", "updated" or "date" front matter value of its template.
+//
+// Usage:
+//
+//	site.File("/sitemap.xml", func() ([]byte, error) {
+//	  return site.Sitemap("https://example.com").XML()
+//	})
+func (s *Site) Sitemap(baseURL string) *Sitemap {
+	sitemap := &Sitemap{BaseURL: baseURL}
+	for _, route := range s.routes {
+		file, err := routeFile(route.Path)
+		if err != nil || path.Ext(file) != ".html" {
+			continue
+		}
+
+		u := SitemapURL{Path: route.Path, LastMod: route.LastMod}
+		if u.LastMod.IsZero() {
+			u.LastMod = lastMod(s.v.FrontMatter(route.Template), time.Time{})
+		}
+		sitemap.URLs = append(sitemap.URLs, u)
+	}
+
+	return sitemap
+}
+
+// absoluteURL returns the "p" path joined to the "baseURL",
+// the "p" as it is if it is an absolute URL already.
+func absoluteURL(baseURL, p string) string {
+	if strings.Contains(p, "://") {
+		return p
+	}
+
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+
+	return strings.TrimSuffix(baseURL, "/") + p
+}
+
+func marshalXML(doc any) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&b)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	b.WriteByte('\n')
+
+	return b.Bytes(), nil
+}
+
+func xmlHandler(contentType string, contents func() ([]byte, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := contents()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		if _, err = w.Write(data); err != nil {
+			// Abort the response, e.g. on a closed connection, instead of serving a truncated document.
+			panic(http.ErrAbortHandler)
+		}
+	})
+}
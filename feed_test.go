@@ -0,0 +1,126 @@
+package blocks_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/blocks"
+)
+
+func TestFeedsAndSitemap(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("index.html", []byte("---\nlastmod: 2024-05-06\n---\n<h1>Home</h1>"), nil)
+	mfs.ParseTemplate("post.html", []byte(`<h1>{{ .Title }}</h1>{{ .Body }}`), nil)
+	mfs.ParseTemplate("posts/first.md", []byte("---\ntitle: First & Foremost\ndate: 2024-01-02\n---\nThe first post."), nil)
+	mfs.ParseTemplate("posts/second.md", []byte("---\ntitle: Second\ndate: 2024-03-04\nlastmod: 2024-04-05\n---\nThe second post."), nil)
+
+	views := blocks.New(mfs).Collection("posts", "posts")
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	const baseURL = "https://example.com/"
+	posts := views.Entries("posts")
+	postPath := func(e *blocks.Entry) string { return "/posts/" + e.Slug }
+
+	feed := &blocks.Feed{Title: "Blog", Author: "kataras", BaseURL: baseURL, Path: "/feed.xml", Items: posts.FeedItems(postPath)}
+
+	outputDir := t.TempDir()
+	site := blocks.NewSite(views)
+	site.OutputDir = outputDir
+	site.Route(blocks.Route{Path: "/", Template: "index"})
+	site.Route(blocks.EntryRoutes(posts, postPath, "post", "")...)
+	site.Route(blocks.Route{Path: "/robots.txt", Template: "index"})
+	site.File("/feed.xml", feed.RSS).File("/atom.xml", feed.Atom).File("/sitemap.xml", func() ([]byte, error) {
+		return site.Sitemap(baseURL).XML()
+	})
+
+	report, err := site.Build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Pages) != 7 {
+		t.Fatalf("expected 7 pages but got %d", len(report.Pages))
+	}
+
+	expected := map[string][]string{
+		"feed.xml": {
+			`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">`,
+			`<atom:link href="https://example.com/feed.xml" rel="self" type="application/rss+xml"></atom:link>`,
+			`<lastBuildDate>Fri, 05 Apr 2024 00:00:00 +0000</lastBuildDate>`,
+			`<title>First &amp; Foremost</title>`,
+			`<link>https://example.com/posts/second</link>`,
+			`<pubDate>Mon, 04 Mar 2024 00:00:00 +0000</pubDate>`,
+			`<description>&lt;p&gt;The second post.&lt;/p&gt;</description>`,
+		},
+		"atom.xml": {
+			`<feed xmlns="http://www.w3.org/2005/Atom">`,
+			`<updated>2024-04-05T00:00:00Z</updated>`,
+			`<author>`,
+			`<id>https://example.com/posts/first</id>`,
+			`<summary type="html">&lt;p&gt;The first post.&lt;/p&gt;</summary>`,
+		},
+		"sitemap.xml": {
+			`<loc>https://example.com/</loc>`,
+			`<lastmod>2024-05-06T00:00:00Z</lastmod>`,
+			`<loc>https://example.com/posts/second</loc>`,
+			`<lastmod>2024-04-05T00:00:00Z</lastmod>`,
+		},
+	}
+	for file, contains := range expected {
+		data, err := os.ReadFile(filepath.Join(outputDir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, s := range contains {
+			if !strings.Contains(string(data), s) {
+				t.Fatalf("%s: expected to contain %s but got:\n%s", file, s, data)
+			}
+		}
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(outputDir, "sitemap.xml")); strings.Contains(string(data), "robots.txt") {
+		t.Fatalf("expected only the HTML pages on the sitemap but got:\n%s", data)
+	}
+
+	rec := httptest.NewRecorder()
+	feed.AtomHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/atom.xml", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/atom+xml; charset=utf-8" || !strings.Contains(rec.Body.String(), "<title>Second</title>") {
+		t.Fatalf("unexpected response: %s\n%s", ct, rec.Body.String())
+	}
+}
+
+func TestFeedWithoutDates(t *testing.T) {
+	feed := &blocks.Feed{Title: "Empty", BaseURL: "https://example.com"}
+	data, err := feed.Atom()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if atom := string(data); strings.Contains(atom, "0001-01-01") || !strings.Contains(atom, "<updated>"+time.Now().Format("2006-")) {
+		t.Fatalf("expected the current time as the updated date but got:\n%s", atom)
+	}
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Fatalf("expected the response to be aborted on a write error but got: %v", r)
+		}
+	}()
+	feed.RSSHandler().ServeHTTP(failingResponseWriter{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/feed.xml", nil))
+}
+
+type failingResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (failingResponseWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection closed")
+}
@@ -0,0 +1,170 @@
+package blocks
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var frontMatterDelim = []byte("---")
+
+// FrontMatter returns the front matter of the "tmplName" content template.
+// The front matter is an optional block at the top of a template file,
+// between two "---" lines, which holds "key: value" pairs, e.g.
+//
+//	---
+//	layout: admin
+//	title: Dashboard
+//	tags: [go, web]
+//	---
+//	<h1>{{ .Title }}</h1>
+//
+// Supported values are strings, numbers, booleans, dates (2006-01-02 and RFC 3339)
+// and lists (inline [a, b] or one "- item" per line).
+// The "layout" key selects the layout of a content template,
+// see `ExecuteTemplateContext` for its precedence.
+//
+// It returns nil if the template does not exist or it has no front matter.
+func (v *Blocks) FrontMatter(tmplName string) map[string]any {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.frontMatter[strings.TrimSuffix(tmplName, v.extension)]
+}
+
+// splitFrontMatter separates the front matter from the rest of the "data".
+// It returns a nil map if the data do not start with a front matter block,
+// including an opening "---" line without a closing one.
+func splitFrontMatter(data []byte) (map[string]any, []byte, error) {
+	if !bytes.HasPrefix(data, frontMatterDelim) {
+		return nil, data, nil
+	}
+
+	rest := data[len(frontMatterDelim):]
+	lineEnd := bytes.IndexByte(rest, '\n')
+	if lineEnd == -1 || len(bytes.TrimSpace(rest[:lineEnd])) > 0 {
+		return nil, data, nil // not a front matter delimiter line, e.g. a markdown "----".
+	}
+	rest = rest[lineEnd+1:]
+
+	var (
+		block []byte
+		body  []byte
+		found bool
+	)
+	for offset := 0; offset <= len(rest); {
+		end := bytes.IndexByte(rest[offset:], '\n')
+		line := rest[offset:]
+		if end != -1 {
+			line = rest[offset : offset+end]
+		}
+
+		if bytes.Equal(bytes.TrimSpace(line), frontMatterDelim) {
+			block = rest[:offset]
+			if end != -1 {
+				body = rest[offset+end+1:]
+			}
+			found = true
+			break
+		}
+
+		if end == -1 {
+			break
+		}
+		offset += end + 1
+	}
+
+	if !found {
+		return nil, data, nil // no closing delimiter line, e.g. a markdown horizontal rule.
+	}
+
+	values, err := parseFrontMatter(string(block))
+	if err != nil {
+		return nil, data, err
+	}
+
+	return values, body, nil
+}
+
+// parseFrontMatter parses a front matter block of "key: value" lines.
+func parseFrontMatter(block string) (map[string]any, error) {
+	values := make(map[string]any)
+
+	var listKey string // the key of a "- item" list in progress.
+	for i, line := range strings.Split(block, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if listKey != "" && strings.HasPrefix(trimmed, "- ") {
+			list, _ := values[listKey].([]any)
+			values[listKey] = append(list, parseFrontMatterValue(strings.TrimSpace(trimmed[2:])))
+			continue
+		}
+		listKey = ""
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("front matter: line %d: expected a key: value pair but got %q", i+1, line)
+		}
+
+		value = strings.TrimSpace(value)
+		if value == "" {
+			listKey = key
+			values[key] = []any{}
+			continue
+		}
+
+		values[key] = parseFrontMatterValue(value)
+	}
+
+	return values, nil
+}
+
+func parseFrontMatterValue(value string) any {
+	if n := len(value); n >= 2 {
+		if (value[0] == '"' && value[n-1] == '"') || (value[0] == '\'' && value[n-1] == '\'') {
+			if s, err := strconv.Unquote(`"` + value[1:n-1] + `"`); err == nil {
+				return s
+			}
+			return value[1 : n-1]
+		}
+
+		if value[0] == '[' && value[n-1] == ']' {
+			list := []any{}
+			for _, item := range strings.Split(value[1:n-1], ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, parseFrontMatterValue(item))
+				}
+			}
+			return list
+		}
+	}
+
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+
+	return value
+}
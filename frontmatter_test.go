@@ -0,0 +1,29 @@
+package blocks_test
+
+import (
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestFrontMatterUnclosed(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("rule.html", []byte("---\n<p>below a rule</p>"), nil)
+
+	views := blocks.New(mfs)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if frontMatter := views.FrontMatter("rule"); frontMatter != nil {
+		t.Fatalf("expected no front matter but got: %v", frontMatter)
+	}
+
+	contents, err := views.TemplateString("rule", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "---\n<p>below a rule</p>"; contents != expected {
+		t.Fatalf("expected the whole input as the body:\n%s\nbut got:\n%s", expected, contents)
+	}
+}
@@ -1,7 +1,6 @@
 package blocks
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"html/template"
@@ -14,7 +13,6 @@ import (
 	"strings"
 	"sync"
 	"time"
-	"unicode"
 )
 
 func getFS(fsOrDir any) fs.FS {
@@ -186,10 +184,6 @@ func readFiles(ctx context.Context, fsys fs.FS, root string) (map[string][]byte,
 			default:
 			}
 
-			// trim top and bottom space.
-			data = bytes.TrimLeftFunc(data, unicode.IsSpace)
-			data = bytes.TrimRightFunc(data, unicode.IsSpace)
-
 			mu.Lock()
 			files[path] = data
 			mu.Unlock()
@@ -223,8 +217,13 @@ func readFiles(ctx context.Context, fsys fs.FS, root string) (map[string][]byte,
 
 // MemoryFileSystem is a custom file system that holds virtual/memory template files in memory.
 // It completes the fs.FS interface.
+// It is safe for concurrent use.
 type MemoryFileSystem struct {
+	mu    sync.RWMutex
 	files map[string]*memoryTemplateFile
+
+	watchersMu sync.Mutex
+	watchers   map[*func([]string)]struct{} // see `Notify`.
 }
 
 // NewMemoryFileSystem creates a new VirtualFileSystem instance.
@@ -241,24 +240,65 @@ func NewMemoryFileSystem() *MemoryFileSystem {
 	}
 }
 
-// Ensure MemoryFileSystem implements fs.FS, fs.ReadDirFS and fs.WalkDirFS interfaces.
+// Ensure MemoryFileSystem implements fs.FS, fs.ReadDirFS and NotifyFS interfaces.
 var (
 	_ fs.FS        = (*MemoryFileSystem)(nil)
 	_ fs.ReadDirFS = (*MemoryFileSystem)(nil)
+	_ NotifyFS     = (*MemoryFileSystem)(nil)
 )
 
 // ParseTemplate adds a new memory temlate to the file system.
 func (vfs *MemoryFileSystem) ParseTemplate(name string, contents []byte, funcMap template.FuncMap) error {
+	vfs.mu.Lock()
 	vfs.files[name] = &memoryTemplateFile{
 		name:     name,
 		contents: contents,
 		funcMap:  funcMap,
+		modTime:  time.Now(),
 	}
+	vfs.mu.Unlock()
+
+	vfs.notify([]string{name})
 	return nil
 }
 
+// Notify implements the `NotifyFS` interface.
+// The "onChange" is called on each `ParseTemplate` until the "ctx" is done.
+func (vfs *MemoryFileSystem) Notify(ctx context.Context, onChange func(changed []string)) error {
+	vfs.watchersMu.Lock()
+	if vfs.watchers == nil {
+		vfs.watchers = make(map[*func([]string)]struct{})
+	}
+	vfs.watchers[&onChange] = struct{}{}
+	vfs.watchersMu.Unlock()
+
+	<-ctx.Done()
+
+	vfs.watchersMu.Lock()
+	delete(vfs.watchers, &onChange)
+	vfs.watchersMu.Unlock()
+
+	return ctx.Err()
+}
+
+func (vfs *MemoryFileSystem) notify(changed []string) {
+	vfs.watchersMu.Lock()
+	watchers := make([]func([]string), 0, len(vfs.watchers))
+	for onChange := range vfs.watchers {
+		watchers = append(watchers, *onChange)
+	}
+	vfs.watchersMu.Unlock()
+
+	for _, onChange := range watchers {
+		onChange(changed)
+	}
+}
+
 // Open implements the fs.FS interface.
 func (mfs *MemoryFileSystem) Open(name string) (fs.File, error) {
+	mfs.mu.RLock()
+	defer mfs.mu.RUnlock()
+
 	if name == "." || name == "/" {
 		// Return a directory representing the root.
 		return &memoryDir{
@@ -276,8 +316,10 @@ func (mfs *MemoryFileSystem) Open(name string) (fs.File, error) {
 	}
 
 	if file, exists := mfs.files[name]; exists {
-		file.reset() // Reset read position
-		return file, nil
+		// Return a new file of the same contents, so its read position is not shared.
+		openFile := *file
+		openFile.reset()
+		return &openFile, nil
 	}
 
 	return nil, fs.ErrNotExist
@@ -285,6 +327,9 @@ func (mfs *MemoryFileSystem) Open(name string) (fs.File, error) {
 
 // ReadDir implements the fs.ReadDirFS interface.
 func (mfs *MemoryFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	mfs.mu.RLock()
+	defer mfs.mu.RUnlock()
+
 	var entries []fs.DirEntry
 	prefix := strings.TrimLeftFunc(name, func(r rune) bool {
 		return r == '.' || r == '/'
@@ -316,8 +361,9 @@ func (mfs *MemoryFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
 		} else {
 			file, _ := mfs.files[fullPath]
 			info := &memoryFileInfo{
-				name: entryName,
-				size: int64(len(file.contents)),
+				name:    entryName,
+				size:    int64(len(file.contents)),
+				modTime: file.modTime,
 			}
 			entries = append(entries, fs.FileInfoToDirEntry(info))
 		}
@@ -435,6 +481,7 @@ type memoryTemplateFile struct {
 	name     string
 	contents []byte
 	funcMap  template.FuncMap
+	modTime  time.Time
 	offset   int64
 }
 
@@ -448,8 +495,9 @@ func (mf *memoryTemplateFile) reset() {
 // Stat implements the fs.File interface, returning file info.
 func (mf *memoryTemplateFile) Stat() (fs.FileInfo, error) {
 	return &memoryFileInfo{
-		name: path.Base(mf.name),
-		size: int64(len(mf.contents)),
+		name:    path.Base(mf.name),
+		size:    int64(len(mf.contents)),
+		modTime: mf.modTime,
 	}, nil
 }
 
@@ -470,8 +518,9 @@ func (mf *memoryTemplateFile) Close() error {
 
 // memoryFileInfo provides file information for a memory file.
 type memoryFileInfo struct {
-	name string
-	size int64
+	name    string
+	size    int64
+	modTime time.Time
 }
 
 // Ensure memoryFileInfo implements fs.FileInfo interface.
@@ -492,9 +541,9 @@ func (fi *memoryFileInfo) Mode() fs.FileMode {
 	return 0444 // Read-only
 }
 
-// ModTime returns modification time.
+// ModTime returns modification time, the time the file was added through `ParseTemplate`.
 func (fi *memoryFileInfo) ModTime() time.Time {
-	return time.Now()
+	return fi.modTime
 }
 
 // IsDir reports if the file is a directory.
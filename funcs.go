@@ -1,11 +1,19 @@
 package blocks
 
-import "html/template"
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"text/template/parse"
+)
 
 var builtins = template.FuncMap{
 	"partial": func(v *Blocks) any {
 		return v.PartialFunc
 	},
+	"collection": func(v *Blocks) any {
+		return v.collectionFunc
+	},
 }
 
 // Register register a function map
@@ -66,3 +74,113 @@ func translateFuncs(v *Blocks, funcMap template.FuncMap) template.FuncMap { // u
 	}
 	return funcs
 }
+
+// MissingFuncs registers the "stub" function under the name of every function
+// which is called by the templates but it is not registered to the engine.
+// The "stub" should be compatible with a standard html/template function,
+// e.g. func(...any) string { return "" }.
+//
+// Useful for tools which load the templates outside of their application,
+// e.g. the "blocks" command's preview server, where the application's functions are not available.
+// It must be called before the engine is loaded.
+func (v *Blocks) MissingFuncs(stub any) *Blocks {
+	v.missingFunc = stub
+	return v
+}
+
+// textTemplateBuiltins are the predefined functions of the text/template package.
+var textTemplateBuiltins = []string{
+	"and", "call", "html", "index", "slice", "js", "len", "not", "or",
+	"print", "printf", "println", "urlquery",
+	"eq", "ge", "gt", "le", "lt", "ne",
+}
+
+// stubMissingFuncs adds the `MissingFuncs` stub to the "funcs" for every
+// function called by the "files" which is not registered to the engine.
+func (v *Blocks) stubMissingFuncs(files []*templateFile, funcs template.FuncMap) error {
+	known := make(map[string]struct{})
+	for _, funcMap := range []template.FuncMap{builtins, v.tmplFuncs, v.layoutFuncs, funcs} {
+		for name := range funcMap {
+			known[name] = struct{}{}
+		}
+	}
+	for _, name := range textTemplateBuiltins {
+		known[name] = struct{}{}
+	}
+
+	for _, file := range files {
+		trees, err := file.parseTrees()
+		if err != nil {
+			return mapError(err, files)
+		}
+
+		for _, tree := range trees {
+			walkNodes(tree.Root, func(node parse.Node) {
+				ident, ok := node.(*parse.IdentifierNode)
+				if !ok {
+					return
+				}
+
+				if _, exists := known[ident.Ident]; !exists {
+					known[ident.Ident] = struct{}{}
+					funcs[ident.Ident] = v.missingFunc
+				}
+			})
+		}
+	}
+
+	return nil
+}
+
+// checkPartials returns an error, located at the call, for the first
+// {{ partial "name" }} call of the "files" whose template does not exist, see `Strict`.
+func (v *Blocks) checkPartials(files []*templateFile) error {
+	var names []string
+	exists := make(map[string]struct{})
+	for _, file := range files {
+		if !file.layout {
+			names = append(names, file.name)
+			exists[file.name] = struct{}{}
+		}
+	}
+
+	for _, file := range files {
+		trees, err := file.parseTrees()
+		if err != nil {
+			return mapError(err, files)
+		}
+
+		for _, tree := range trees {
+			walkNodes(tree.Root, func(node parse.Node) {
+				cmd, ok := node.(*parse.CommandNode)
+				if !ok || err != nil || len(cmd.Args) < 2 {
+					return
+				}
+
+				if ident, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || ident.Ident != "partial" {
+					return
+				}
+
+				str, ok := cmd.Args[1].(*parse.StringNode)
+				if !ok {
+					return // not a literal name, it is checked on execution.
+				}
+
+				name := strings.TrimSuffix(str.Text, v.extension)
+				if _, ok = exists[name]; ok {
+					return
+				}
+
+				line, col := file.position(int(cmd.Position()))
+				notExistErr := &TemplateNotExistError{Name: name, Key: name, Suggestions: suggest(name, names)}
+				err = fmt.Errorf("template: %s:%d:%d: unknown partial: %w", file.filename, line, col, notExistErr)
+			})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
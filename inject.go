@@ -0,0 +1,56 @@
+package blocks
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+)
+
+// injector returns the HTML to be injected right before the closing body tag
+// of the pages rendered with a layout, e.g. the live reload client script.
+type injector func(ctx context.Context, tmplName, layoutName string, data any) string
+
+var closingBodyTag = []byte("</body>")
+
+// executePage executes the template and injects the registered injectors' HTML to its output,
+// if it's rendered with a layout. The output is buffered on that case,
+// so nothing is written to "w" on errors.
+func (v *Blocks) executePage(ctx context.Context, w io.Writer, tmplName, layoutName string, data any) error {
+	if layoutName == "" || len(v.injectors) == 0 {
+		return v.executeTemplate(ctx, w, tmplName, layoutName, data)
+	}
+
+	b := v.bufferPool.Get()
+	defer v.bufferPool.Put(b)
+
+	if err := v.executeTemplate(ctx, b, tmplName, layoutName, data); err != nil {
+		return err
+	}
+
+	var snippets strings.Builder
+	for _, inject := range v.injectors {
+		snippets.WriteString(inject(ctx, tmplName, layoutName, data))
+	}
+
+	_, err := w.Write(injectBeforeBody(b.Bytes(), snippets.String()))
+	return err
+}
+
+// injectBeforeBody inserts the "snippet" right before the last closing body tag
+// of the "page", or at its end if the page has no body tag.
+func injectBeforeBody(page []byte, snippet string) []byte {
+	if snippet == "" {
+		return page
+	}
+
+	idx := bytes.LastIndex(bytes.ToLower(page), closingBodyTag)
+	if idx == -1 {
+		return append(page, snippet...)
+	}
+
+	out := make([]byte, 0, len(page)+len(snippet))
+	out = append(out, page[:idx]...)
+	out = append(out, snippet...)
+	return append(out, page[idx:]...)
+}
@@ -0,0 +1,65 @@
+package blocks_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestLayoutPrecedence(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`main:{{ yield . }}`), nil)
+	mfs.ParseTemplate("layouts/admin.html", []byte(`admin:{{ yield . }}`), nil)
+	mfs.ParseTemplate("layouts/dark/admin.html", []byte(`dark-admin:{{ yield . }}`), nil)
+	mfs.ParseTemplate("layouts/article.html", []byte(`article:{{ yield . }}`), nil)
+	mfs.ParseTemplate("index.html", []byte(`index`), nil)
+	mfs.ParseTemplate("index.el.html", []byte(`ευρετήριο`), nil)
+	mfs.ParseTemplate("post.html", []byte("---\nlayout: article\ntitle: \"My Post\"\ntags: [go, web]\n---\npost"), nil)
+
+	views := blocks.New(mfs).DefaultLayout("main")
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := views.FrontMatter("post")["title"]; got != "My Post" {
+		t.Fatalf("expected front matter title but got: %v", got)
+	}
+
+	tests := []struct {
+		middleware func(http.Handler) http.Handler
+		tmplName   string
+		layoutName string
+		expected   string
+	}{
+		{nil, "index", "", "main:index"},
+		{nil, "post", "", "article:post"},
+		{nil, "post", "admin", "admin:post"},
+		{blocks.SetLayout("admin"), "index", "", "admin:index"},
+		{blocks.SetLayout("admin"), "post", "", "admin:post"},
+		{blocks.SetLayout("admin"), "index", "main", "main:index"},
+		{blocks.SetTheme("dark"), "index", "admin", "dark-admin:index"},
+		{blocks.SetTheme("dark"), "index", "", "main:index"},
+		{blocks.SetLocale("el"), "index", "", "main:ευρετήριο"},
+		{blocks.SetLocale("de"), "index", "", "main:index"},
+	}
+
+	for i, tt := range tests {
+		var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := views.Render(w, r, tt.tmplName, tt.layoutName, nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if tt.middleware != nil {
+			handler = tt.middleware(handler)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if got := rec.Body.String(); got != tt.expected {
+			t.Errorf("[%d] expected: %q but got: %q", i, tt.expected, got)
+		}
+	}
+}
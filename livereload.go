@@ -0,0 +1,203 @@
+package blocks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+// DefaultLiveReloadEndpoint is the default path of the live reload's Server-Sent Events endpoint.
+const DefaultLiveReloadEndpoint = "/_blocks/livereload"
+
+// Live reload events, see `LiveReload.Broadcast`.
+const (
+	// LiveReloadEvent tells the browser to reload the page.
+	LiveReloadEvent = "reload"
+	// LiveReloadCSSEvent tells the browser to re-fetch the page's stylesheets only.
+	LiveReloadCSSEvent = "css"
+)
+
+// LiveReload is a development helper which reloads the browser on changes.
+// It watches the engine's file system (see `Watch`), reloads the templates
+// and notifies the connected browsers through Server-Sent Events.
+// When only stylesheets (.css files) change, the browsers re-fetch their stylesheets
+// instead of reloading the whole page.
+//
+// A tiny client script, which connects to the `Endpoint`,
+// is injected right before the closing body tag of each page rendered with a layout.
+// The script is injected only on `Reload` mode, so the same engine can build
+// a static site (see `Site`) or serve production pages without it.
+//
+// Usage:
+//
+//	views.Reload(true)
+//	lr := blocks.NewLiveReload(views)
+//	go lr.Watch(ctx, os.DirFS("./public"))
+//	http.Handle(lr.Endpoint, lr)
+type LiveReload struct {
+	v *Blocks
+	// Endpoint is the path the LiveReload handler is registered to,
+	// the client script connects to it. Defaults to `DefaultLiveReloadEndpoint`.
+	Endpoint string
+	// Interval is the interval to poll the file systems for changes,
+	// defaults to `DefaultWatchInterval`.
+	Interval time.Duration
+	// OnReload, if not nil, is called after the templates are reloaded.
+	OnReload func(changed []string, err error)
+
+	mu      sync.Mutex
+	clients map[chan string]struct{}
+}
+
+var _ http.Handler = (*LiveReload)(nil)
+
+// NewLiveReload returns a new LiveReload of the "v" engine
+// and registers its client script to the engine's pages.
+// It must be called before the engine is used to render.
+func NewLiveReload(v *Blocks) *LiveReload {
+	lr := &LiveReload{
+		v:        v,
+		Endpoint: DefaultLiveReloadEndpoint,
+		Interval: DefaultWatchInterval,
+		clients:  make(map[chan string]struct{}),
+	}
+	v.injectors = append(v.injectors, lr.inject)
+	return lr
+}
+
+// Watch watches the engine's file system and the optional "assets" file systems,
+// e.g. a directory of stylesheets, and notifies the browsers on changes.
+// Changes on the engine's file system reload the templates too.
+// It blocks until the "ctx" is done.
+func (lr *LiveReload) Watch(ctx context.Context, assets ...fs.FS) error {
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, len(assets)+1)
+	)
+
+	watch := func(fsys fs.FS, onChange func([]string)) {
+		defer wg.Done()
+		if err := watchFS(ctx, fsys, lr.Interval, onChange); err != nil && ctx.Err() == nil {
+			errs <- err
+		}
+	}
+
+	wg.Add(1 + len(assets))
+	go watch(lr.v.fs, func(changed []string) {
+		err := lr.v.LoadWithContext(ctx)
+		if lr.OnReload != nil {
+			lr.OnReload(changed, err)
+		}
+
+		lr.Broadcast(liveReloadEventOf(changed))
+	})
+	for _, fsys := range assets {
+		go watch(fsys, func(changed []string) {
+			lr.Broadcast(liveReloadEventOf(changed))
+		})
+	}
+
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+// liveReloadEventOf returns the css event if all "changed" files are stylesheets.
+func liveReloadEventOf(changed []string) string {
+	for _, name := range changed {
+		if path.Ext(name) != ".css" {
+			return LiveReloadEvent
+		}
+	}
+
+	return LiveReloadCSSEvent
+}
+
+// Broadcast sends the "event" (`LiveReloadEvent` or `LiveReloadCSSEvent`)
+// to all connected browsers.
+func (lr *LiveReload) Broadcast(event string) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	for client := range lr.clients {
+		select {
+		case client <- event:
+		default: // the client is slow, it will get the next one.
+		}
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+// It serves the Server-Sent Events stream the client script listens to.
+func (lr *LiveReload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan string, 1)
+	lr.mu.Lock()
+	lr.clients[client] = struct{}{}
+	lr.mu.Unlock()
+
+	defer func() {
+		lr.mu.Lock()
+		delete(lr.clients, client)
+		lr.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-client:
+			fmt.Fprintf(w, "event: %s\ndata: %d\n\n", event, time.Now().UnixMilli())
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+// inject implements the engine's injector, it returns the client script on `Reload` mode.
+func (lr *LiveReload) inject(context.Context, string, string, any) string {
+	if !lr.v.reload {
+		return ""
+	}
+
+	endpoint, _ := json.Marshal(lr.Endpoint)
+	return fmt.Sprintf(liveReloadScript, endpoint)
+}
+
+const liveReloadScript = `<script>(function () {
+	var source = new EventSource(%s);
+	source.addEventListener("reload", function () { location.reload(); });
+	source.addEventListener("css", function () {
+		document.querySelectorAll('link[rel="stylesheet"]').forEach(function (link) {
+			var url = new URL(link.href);
+			url.searchParams.set("livereload", Date.now());
+			link.href = url.toString();
+		});
+	});
+})();</script>`
@@ -0,0 +1,100 @@
+package blocks_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/blocks"
+)
+
+func TestLiveReload(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<html><body>{{ yield . }}</body></html>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>Index</h1>`), nil)
+
+	views := blocks.New(mfs)
+	lr := blocks.NewLiveReload(views)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := views.TemplateString("index", "main", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(page, "EventSource") {
+		t.Fatalf("expected no client script out of the reload mode but got:\n%s", page)
+	}
+
+	views.Reload(true)
+	page, err = views.TemplateString("index", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(page, "EventSource") {
+		t.Fatalf("expected no client script on templates without layout but got:\n%s", page)
+	}
+
+	var b strings.Builder
+	if err = views.ExecuteTemplate(&b, "index", "main", nil); err != nil {
+		t.Fatal(err)
+	}
+	if page = b.String(); !strings.HasPrefix(page, "<html><body><h1>Index</h1><script>") || !strings.HasSuffix(page, "</script></body></html>") ||
+		!strings.Contains(page, `new EventSource("`+blocks.DefaultLiveReloadEndpoint+`")`) {
+		t.Fatalf("expected the client script before the closing body tag but got:\n%s", page)
+	}
+
+	srv := httptest.NewServer(lr)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go lr.Watch(ctx)
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	if !scanner.Scan() || scanner.Text() != ": connected" {
+		t.Fatalf("expected the connected comment but got: %q", scanner.Text())
+	}
+
+	events := make(chan string)
+	go func() {
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "event: ") {
+				events <- strings.TrimPrefix(line, "event: ")
+			}
+		}
+	}()
+
+	// wait for the watcher to subscribe to the file system's notifications.
+	time.Sleep(50 * time.Millisecond)
+
+	for _, tt := range []struct {
+		filename string
+		event    string
+	}{
+		{"style.css", blocks.LiveReloadCSSEvent},
+		{"index.html", blocks.LiveReloadEvent},
+	} {
+		mfs.ParseTemplate(tt.filename, []byte(`<h1>Index v2</h1>`), nil)
+
+		select {
+		case event := <-events:
+			if event != tt.event {
+				t.Fatalf("%s: expected event %q but got %q", tt.filename, tt.event, event)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: timeout waiting for the %q event", tt.filename, tt.event)
+		}
+	}
+}
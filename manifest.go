@@ -0,0 +1,255 @@
+package blocks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// buildManifest keeps the inputs of each page of the last incremental build,
+// see `Site.Incremental`.
+type buildManifest struct {
+	// Pages are keyed by their file, relative to the output directory.
+	Pages map[string]manifestPage `json:"pages"`
+	// Assets are the copied static files.
+	Assets []string `json:"assets,omitempty"`
+}
+
+type manifestPage struct {
+	Path string `json:"path"`
+	// Inputs maps each input of the page to the digest of its contents, e.g.
+	// "template:index.html", "layout:layouts/main.html", "partial:footer.html", "data", "funcs" and "collections".
+	Inputs map[string]string `json:"inputs"`
+	Bytes  int               `json:"bytes"`
+}
+
+// manifestFile returns the manifest file of the site, see `Site.ManifestFile`.
+func (s *Site) manifestFile(outputDir string) string {
+	if s.ManifestFile != "" {
+		return s.ManifestFile
+	}
+
+	return filepath.Clean(outputDir) + ".manifest.json"
+}
+
+// readManifest reads the "filename" manifest, an empty one if it does not exist.
+func readManifest(filename string) (*buildManifest, error) {
+	m := &buildManifest{Pages: make(map[string]manifestPage)}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return m, nil
+		}
+
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("blocks: build: manifest: %s: %w", filename, err)
+	}
+
+	if m.Pages == nil {
+		m.Pages = make(map[string]manifestPage)
+	}
+
+	return m, nil
+}
+
+func (m *buildManifest) write(filename string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(filename, data)
+}
+
+// pageInputs returns the digests of the template files a page is rendered with:
+// its content template, its layout and the partials they call, transitively.
+// A partial of a non literal name may be any template, so all of them are inputs then.
+func (v *Blocks) pageInputs(tmplName, layoutName string, funcsDigest string) map[string]string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	inputs := map[string]string{"funcs": funcsDigest}
+
+	files := make(map[string]*templateFile) // content template name -> file.
+	for _, file := range v.files {
+		if !file.layout {
+			files[file.name] = file
+		}
+	}
+
+	var (
+		dynamic bool
+		visit   func(file *templateFile, kind string)
+	)
+	visit = func(file *templateFile, kind string) {
+		key := kind + ":" + file.filename
+		if _, visited := inputs[key]; visited {
+			return
+		}
+		inputs[key] = digest([]byte(file.source))
+
+		if len(v.collections) > 0 && callsFunc(file, "collection") {
+			inputs["collections"] = v.collectionsDigest
+		}
+
+		names, ok := partialNames(file)
+		if !ok {
+			dynamic = true
+		}
+
+		for _, name := range names {
+			if partial, ok := files[strings.TrimSuffix(name, v.extension)]; ok {
+				visit(partial, "partial")
+			}
+		}
+	}
+
+	tmplName, layoutName = v.resolveLoadedNames(context.Background(), tmplName, layoutName)
+	if file, ok := files[tmplName]; ok {
+		visit(file, "template")
+	}
+
+	if layoutName != "" {
+		for _, file := range v.files {
+			if file.layout && file.name == layoutName {
+				visit(file, "layout")
+			}
+		}
+	}
+
+	if dynamic {
+		for _, file := range files {
+			visit(file, "partial")
+		}
+	}
+
+	return inputs
+}
+
+// partialNames returns the literal names of the {{ partial "name" }} calls of the "file".
+// It reports false if the file calls a partial of a non literal name too.
+func partialNames(file *templateFile) ([]string, bool) {
+	trees, err := file.parseTrees()
+	if err != nil {
+		return nil, false
+	}
+
+	var (
+		names   []string
+		literal = true
+	)
+	for _, tree := range trees {
+		walkNodes(tree.Root, func(node parse.Node) {
+			cmd, ok := node.(*parse.CommandNode)
+			if !ok || len(cmd.Args) < 2 {
+				return
+			}
+
+			if ident, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || ident.Ident != "partial" {
+				return
+			}
+
+			if str, ok := cmd.Args[1].(*parse.StringNode); ok {
+				names = append(names, str.Text)
+			} else {
+				literal = false
+			}
+		})
+	}
+
+	return names, literal
+}
+
+// callsFunc reports whether the "file" calls the "funcName" function.
+func callsFunc(file *templateFile, funcName string) bool {
+	trees, err := file.parseTrees()
+	if err != nil {
+		return false
+	}
+
+	found := false
+	for _, tree := range trees {
+		walkNodes(tree.Root, func(node parse.Node) {
+			if ident, ok := node.(*parse.IdentifierNode); ok && ident.Ident == funcName {
+				found = true
+			}
+		})
+	}
+
+	return found
+}
+
+// funcsDigest returns the digest of the engine's function set,
+// their names and signatures, and the "version".
+func (v *Blocks) funcsDigest(version string) string {
+	funcs := make(map[string]string)
+	for _, funcMap := range []map[string]any{v.tmplFuncs, v.layoutFuncs} {
+		for name, fn := range funcMap {
+			funcs[name] = reflect.TypeOf(fn).String()
+		}
+	}
+	for name, fn := range v.contextFuncs {
+		funcs[name] = fn.Type().String()
+	}
+
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(version)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n%s %s", name, funcs[name])
+	}
+
+	return digest([]byte(b.String()))
+}
+
+// dataDigest returns the digest of a page's data, of its JSON form if possible.
+func dataDigest(data any) string {
+	b, err := json.Marshal(data)
+	if err != nil {
+		b = fmt.Appendf(nil, "%#v", data)
+	}
+
+	return digest(b)
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// removeStale removes the "filename" file of the "outputDir"
+// and its parent directories which become empty.
+func removeStale(outputDir, filename string) error {
+	name := filepath.Join(outputDir, filepath.FromSlash(filename))
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	root := filepath.Clean(outputDir)
+	for dir := filepath.Dir(name); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break // not empty.
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,407 @@
+package blocks
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Observer is the interface which can be registered through `Observe`
+// to get notified about the engine's loads and renders,
+// e.g. to collect metrics or to start and end tracing spans.
+// The start and finish events of the same render share the same ID.
+//
+// Its methods are called synchronously, from the goroutine
+// which loads or renders the templates, so they should return fast.
+// See `Hooks` and `Collector` for ready-made implementations.
+type Observer interface {
+	LoadStart(LoadStartEvent)
+	LoadFinish(LoadEvent)
+	RenderStart(RenderStartEvent)
+	RenderFinish(RenderEvent)
+	PartialRender(PartialEvent)
+}
+
+// LoadStartEvent is the event of `Observer.LoadStart`.
+type LoadStartEvent struct {
+	Start time.Time
+}
+
+// LoadEvent is the event of `Observer.LoadFinish`.
+type LoadEvent struct {
+	Start    time.Time
+	Duration time.Duration
+	// Files holds the template file names, relative to the file system's root.
+	Files []string
+	Err   error
+}
+
+// RenderStartEvent is the event of `Observer.RenderStart`.
+type RenderStartEvent struct {
+	ID       uint64
+	Context  context.Context
+	Template string
+	Layout   string // empty when rendered without a layout.
+	Start    time.Time
+}
+
+// RenderEvent is the event of `Observer.RenderFinish`.
+type RenderEvent struct {
+	ID       uint64
+	Context  context.Context
+	Template string
+	Layout   string
+	Start    time.Time
+	Duration time.Duration
+	// Bytes is the number of bytes written to the output.
+	Bytes int64
+	Err   error
+}
+
+// PartialEvent is the event of `Observer.PartialRender`,
+// fired after a {{ partial }} call completes.
+type PartialEvent struct {
+	// RenderID is the ID of the render the partial was called from,
+	// zero if the partial was rendered through `PartialFunc`.
+	RenderID uint64
+	Context  context.Context
+	Template string
+	Start    time.Time
+	Duration time.Duration
+	Bytes    int64
+	Err      error
+}
+
+// Observe registers one or more observers to the engine.
+// It should be called before the engine is loaded.
+func (v *Blocks) Observe(observers ...Observer) *Blocks {
+	v.observers = append(v.observers, observers...)
+	return v
+}
+
+var renderIDs atomic.Uint64
+
+type renderContextKey struct{}
+
+// renderInfo is the render in progress, stored in the execution's context.
+type renderInfo struct {
+	id       uint64
+	template string
+	layout   string
+}
+
+func renderFromContext(ctx context.Context) *renderInfo {
+	info, _ := ctx.Value(renderContextKey{}).(*renderInfo)
+	return info
+}
+
+// observeRender executes "render" and notifies the observers, if any.
+func (v *Blocks) observeRender(ctx context.Context, w io.Writer, tmplName, layoutName string, render func(context.Context, io.Writer) error) error {
+	if len(v.observers) == 0 {
+		return render(ctx, w)
+	}
+
+	info := &renderInfo{id: renderIDs.Add(1), template: tmplName, layout: layoutName}
+	ctx = context.WithValue(ctx, renderContextKey{}, info)
+
+	start := time.Now()
+	for _, o := range v.observers {
+		o.RenderStart(RenderStartEvent{ID: info.id, Context: ctx, Template: tmplName, Layout: layoutName, Start: start})
+	}
+
+	cw := &countWriter{w: w}
+	err := render(ctx, cw)
+
+	evt := RenderEvent{
+		ID:       info.id,
+		Context:  ctx,
+		Template: tmplName,
+		Layout:   layoutName,
+		Start:    start,
+		Duration: time.Since(start),
+		Bytes:    cw.n,
+		Err:      err,
+	}
+	for _, o := range v.observers {
+		o.RenderFinish(evt)
+	}
+
+	return err
+}
+
+func (v *Blocks) notifyPartial(ctx context.Context, partialName string, start time.Time, bytes int, err error) {
+	if len(v.observers) == 0 {
+		return
+	}
+
+	evt := PartialEvent{
+		Context:  ctx,
+		Template: partialName,
+		Start:    start,
+		Duration: time.Since(start),
+		Bytes:    int64(bytes),
+		Err:      err,
+	}
+	if info := renderFromContext(ctx); info != nil {
+		evt.RenderID = info.id
+	}
+
+	for _, o := range v.observers {
+		o.PartialRender(evt)
+	}
+}
+
+func (v *Blocks) notifyLoadStart(start time.Time) {
+	for _, o := range v.observers {
+		o.LoadStart(LoadStartEvent{Start: start})
+	}
+}
+
+func (v *Blocks) notifyLoadFinish(start time.Time, files []string, err error) {
+	evt := LoadEvent{Start: start, Duration: time.Since(start), Files: files, Err: err}
+	for _, o := range v.observers {
+		o.LoadFinish(evt)
+	}
+}
+
+// countWriter counts the bytes written to "w".
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// Hooks is an `Observer` implementation of optional functions.
+type Hooks struct {
+	OnLoadStart     func(LoadStartEvent)
+	OnLoadFinish    func(LoadEvent)
+	OnRenderStart   func(RenderStartEvent)
+	OnRenderFinish  func(RenderEvent)
+	OnPartialRender func(PartialEvent)
+}
+
+var _ Observer = Hooks{}
+
+// LoadStart implements the `Observer` interface.
+func (h Hooks) LoadStart(evt LoadStartEvent) {
+	if h.OnLoadStart != nil {
+		h.OnLoadStart(evt)
+	}
+}
+
+// LoadFinish implements the `Observer` interface.
+func (h Hooks) LoadFinish(evt LoadEvent) {
+	if h.OnLoadFinish != nil {
+		h.OnLoadFinish(evt)
+	}
+}
+
+// RenderStart implements the `Observer` interface.
+func (h Hooks) RenderStart(evt RenderStartEvent) {
+	if h.OnRenderStart != nil {
+		h.OnRenderStart(evt)
+	}
+}
+
+// RenderFinish implements the `Observer` interface.
+func (h Hooks) RenderFinish(evt RenderEvent) {
+	if h.OnRenderFinish != nil {
+		h.OnRenderFinish(evt)
+	}
+}
+
+// PartialRender implements the `Observer` interface.
+func (h Hooks) PartialRender(evt PartialEvent) {
+	if h.OnPartialRender != nil {
+		h.OnPartialRender(evt)
+	}
+}
+
+// Stats holds the render statistics of a template and layout pair (or a partial).
+type Stats struct {
+	Template      string
+	Layout        string
+	Count         int
+	Errors        int
+	Bytes         int64
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+}
+
+// AvgDuration returns the average render duration.
+func (s Stats) AvgDuration() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.TotalDuration / time.Duration(s.Count)
+}
+
+// ErrorRate returns the errors to renders ratio, from 0 to 1.
+func (s Stats) ErrorRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return float64(s.Errors) / float64(s.Count)
+}
+
+func (s *Stats) add(d time.Duration, bytes int64, err error) {
+	s.Count++
+	s.Bytes += bytes
+	s.TotalDuration += d
+	if d > s.MaxDuration {
+		s.MaxDuration = d
+	}
+	if err != nil {
+		s.Errors++
+	}
+}
+
+// Snapshot is a point-in-time copy of a `Collector`'s statistics.
+type Snapshot struct {
+	Loads      int
+	LoadErrors int
+	LastLoad   LoadEvent
+	// Renders and Partials are sorted by template and layout names.
+	Renders  []Stats
+	Partials []Stats
+}
+
+// Render returns the statistics of the "tmplName" and "layoutName" pair.
+func (s Snapshot) Render(tmplName, layoutName string) Stats {
+	for _, stats := range s.Renders {
+		if stats.Template == tmplName && stats.Layout == layoutName {
+			return stats
+		}
+	}
+
+	return Stats{Template: tmplName, Layout: layoutName}
+}
+
+// Partial returns the statistics of the "partialName" partial template.
+func (s Snapshot) Partial(partialName string) Stats {
+	for _, stats := range s.Partials {
+		if stats.Template == partialName {
+			return stats
+		}
+	}
+
+	return Stats{Template: partialName}
+}
+
+// Collector is an in-memory `Observer` which collects
+// render counts, durations and error rates per template.
+// It is safe for concurrent use.
+//
+// Usage:
+//
+//	collector := blocks.NewCollector()
+//	views := blocks.New("./views").Observe(collector)
+//	[...]
+//	stats := collector.Snapshot().Render("index", "main")
+type Collector struct {
+	mu       sync.Mutex
+	snapshot Snapshot
+	renders  map[[2]string]*Stats
+	partials map[string]*Stats
+}
+
+var _ Observer = (*Collector)(nil)
+
+// NewCollector returns a new, empty, in-memory collector.
+func NewCollector() *Collector {
+	return &Collector{
+		renders:  make(map[[2]string]*Stats),
+		partials: make(map[string]*Stats),
+	}
+}
+
+// LoadStart implements the `Observer` interface.
+func (c *Collector) LoadStart(LoadStartEvent) {}
+
+// LoadFinish implements the `Observer` interface.
+func (c *Collector) LoadFinish(evt LoadEvent) {
+	c.mu.Lock()
+	c.snapshot.Loads++
+	if evt.Err != nil {
+		c.snapshot.LoadErrors++
+	}
+	c.snapshot.LastLoad = evt
+	c.mu.Unlock()
+}
+
+// RenderStart implements the `Observer` interface.
+func (c *Collector) RenderStart(RenderStartEvent) {}
+
+// RenderFinish implements the `Observer` interface.
+func (c *Collector) RenderFinish(evt RenderEvent) {
+	key := [2]string{evt.Template, evt.Layout}
+
+	c.mu.Lock()
+	stats, ok := c.renders[key]
+	if !ok {
+		stats = &Stats{Template: evt.Template, Layout: evt.Layout}
+		c.renders[key] = stats
+	}
+	stats.add(evt.Duration, evt.Bytes, evt.Err)
+	c.mu.Unlock()
+}
+
+// PartialRender implements the `Observer` interface.
+func (c *Collector) PartialRender(evt PartialEvent) {
+	c.mu.Lock()
+	stats, ok := c.partials[evt.Template]
+	if !ok {
+		stats = &Stats{Template: evt.Template}
+		c.partials[evt.Template] = stats
+	}
+	stats.add(evt.Duration, evt.Bytes, evt.Err)
+	c.mu.Unlock()
+}
+
+// Snapshot returns a copy of the collected statistics.
+func (c *Collector) Snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := c.snapshot
+	snapshot.Renders = make([]Stats, 0, len(c.renders))
+	for _, stats := range c.renders {
+		snapshot.Renders = append(snapshot.Renders, *stats)
+	}
+	sort.Slice(snapshot.Renders, func(i, j int) bool {
+		a, b := snapshot.Renders[i], snapshot.Renders[j]
+		if a.Template != b.Template {
+			return a.Template < b.Template
+		}
+		return a.Layout < b.Layout
+	})
+
+	snapshot.Partials = make([]Stats, 0, len(c.partials))
+	for _, stats := range c.partials {
+		snapshot.Partials = append(snapshot.Partials, *stats)
+	}
+	sort.Slice(snapshot.Partials, func(i, j int) bool {
+		return snapshot.Partials[i].Template < snapshot.Partials[j].Template
+	})
+
+	return snapshot
+}
+
+// Reset clears the collected statistics.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	c.snapshot = Snapshot{}
+	clearMap(c.renders)
+	clearMap(c.partials)
+	c.mu.Unlock()
+}
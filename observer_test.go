@@ -0,0 +1,75 @@
+package blocks_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestObserver(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<main>{{ yield . }}</main>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>{{ .Title }}</h1>{{ partial "partials/footer" . }}`), nil)
+	mfs.ParseTemplate("partials/footer.html", []byte(`<footer></footer>`), nil)
+
+	var (
+		collector = blocks.NewCollector()
+		started   []string
+		partialOf []uint64
+		renderIDs []uint64
+	)
+	views := blocks.New(mfs).Observe(collector, blocks.Hooks{
+		OnRenderStart: func(evt blocks.RenderStartEvent) {
+			started = append(started, evt.Template+"@"+evt.Layout)
+		},
+		OnRenderFinish: func(evt blocks.RenderEvent) {
+			renderIDs = append(renderIDs, evt.ID)
+		},
+		OnPartialRender: func(evt blocks.PartialEvent) {
+			partialOf = append(partialOf, evt.RenderID)
+		},
+	})
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := views.ExecuteTemplate(new(strings.Builder), "index", "main", map[string]any{"Title": "Hello"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := views.ExecuteTemplate(new(strings.Builder), "missing", "", nil); !errors.As(err, new(blocks.ErrNotExist)) {
+		t.Fatalf("expected not exist error but got: %v", err)
+	}
+
+	snapshot := collector.Snapshot()
+	if snapshot.Loads != 1 || len(snapshot.LastLoad.Files) != 3 {
+		t.Fatalf("expected a single load of 3 files but got: %d loads: %v", snapshot.Loads, snapshot.LastLoad.Files)
+	}
+
+	index := snapshot.Render("index", "main")
+	if index.Count != 3 || index.Errors != 0 || index.Bytes != 3*int64(len("<main><h1>Hello</h1><footer></footer></main>")) {
+		t.Fatalf("unexpected index stats: %#+v", index)
+	}
+
+	if missing := snapshot.Render("missing", ""); missing.Count != 1 || missing.ErrorRate() != 1 {
+		t.Fatalf("unexpected missing template stats: %#+v", missing)
+	}
+
+	if footer := snapshot.Partial("partials/footer"); footer.Count != 3 {
+		t.Fatalf("unexpected partial stats: %#+v", footer)
+	}
+
+	if expected := "index@main,index@main,index@main,missing@"; strings.Join(started, ",") != expected {
+		t.Fatalf("expected render starts: %s but got: %v", expected, started)
+	}
+
+	for i, id := range partialOf {
+		if id == 0 || id != renderIDs[i] {
+			t.Fatalf("expected partial to be associated with render %d but got: %d", renderIDs[i], id)
+		}
+	}
+}
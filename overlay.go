@@ -0,0 +1,236 @@
+package blocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// errorSourceContext is the number of source lines
+// shown before and after the failing line of a `TemplateError`.
+const errorSourceContext = 5
+
+// TemplateError is the error of a failed load or render on `Reload` (development) mode.
+// It wraps the original error and describes where it happened,
+// see `RenderError` for its HTML page.
+type TemplateError struct {
+	// Err is the original error.
+	Err error
+	// Filename, Line and Column locate the failure, when known.
+	Filename     string
+	Line, Column int
+	// Source holds the lines around the failing one.
+	Source []SourceLine
+	// Stack is the template call stack, the outermost first:
+	// the layout, the content template and the partials.
+	Stack []StackFrame
+}
+
+// StackFrame is a template of the `TemplateError.Stack`.
+type StackFrame struct {
+	Kind     string // layout, template or partial.
+	Name     string
+	Filename string
+	// DataType is the type of the data the template is rendered with, e.g. "main.Page".
+	DataType string
+}
+
+// SourceLine is a line of the `TemplateError.Source`.
+type SourceLine struct {
+	Number  int
+	Text    string
+	Current bool // the failing line.
+}
+
+// Error implements the `error` interface, it returns the original error's message.
+func (e *TemplateError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the original error.
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// errorLocationRegex matches the location of text/template and html/template errors,
+// e.g. "template: index.html:2:9:" and "html/template:index.html:2:9:".
+var errorLocationRegex = regexp.MustCompile(`template: ?([^:]+):(\d+)(?::(\d+))?:`)
+
+// templateError returns a `TemplateError` of "err" called by the "stack" templates.
+// If "err" wraps a `TemplateError`, e.g. of a partial, its stack is appended
+// and its location is kept, as it is where the failure happened.
+func (v *Blocks) templateError(err error, stack ...StackFrame) *TemplateError {
+	te := &TemplateError{Err: err, Stack: stack}
+
+	var inner *TemplateError
+	if errors.As(err, &inner) {
+		te.Stack = append(te.Stack, inner.Stack...)
+		te.Filename, te.Line, te.Column, te.Source = inner.Filename, inner.Line, inner.Column, inner.Source
+		return te
+	}
+
+	matches := errorLocationRegex.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return te
+	}
+
+	te.Filename = matches[1]
+	te.Line, _ = strconv.Atoi(matches[2])
+	te.Column, _ = strconv.Atoi(matches[3])
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	for _, file := range v.files {
+		// The locations are mapped to the source files, see `mapError`.
+		if file.filename == te.Filename || (file.layout && file.name == te.Filename) {
+			te.Filename = file.filename
+			te.Source = sourceLines(file.source, te.Line)
+			break
+		}
+	}
+
+	return te
+}
+
+// stackFrame returns the frame of the "kind" template (or layout) "name".
+func (v *Blocks) stackFrame(kind, name string, data any) StackFrame {
+	frame := StackFrame{Kind: kind, Name: name, DataType: fmt.Sprintf("%T", data)}
+
+	layout := kind == "layout"
+	v.mu.RLock()
+	for _, file := range v.files {
+		if file.layout == layout && file.name == name {
+			frame.Filename = file.filename
+			break
+		}
+	}
+	v.mu.RUnlock()
+
+	return frame
+}
+
+// renderError returns the `TemplateError` of a failed render on `Reload` mode,
+// otherwise, or if the "ctx" is done, it returns "err" as it is.
+func (v *Blocks) renderError(ctx context.Context, err error, tmplName, layoutName string, data any) error {
+	if !v.reload || ctx.Err() != nil {
+		return err
+	}
+
+	var stack []StackFrame
+	if layoutName != "" {
+		stack = append(stack, v.stackFrame("layout", layoutName, data))
+	}
+	stack = append(stack, v.stackFrame("template", tmplName, data))
+
+	return v.templateError(err, stack...)
+}
+
+// sourceLines returns the lines of "contents" around the "line".
+func sourceLines(contents string, line int) []SourceLine {
+	lines := strings.Split(contents, "\n")
+	if line < 1 || line > len(lines) {
+		return nil
+	}
+
+	from := max(line-errorSourceContext, 1)
+	to := min(line+errorSourceContext, len(lines))
+
+	source := make([]SourceLine, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		source = append(source, SourceLine{Number: n, Text: lines[n-1], Current: n == line})
+	}
+
+	return source
+}
+
+// RenderError responds with the development error page of "err",
+// a `TemplateError` returned on `Reload` mode, which shows the failing file,
+// its source around the failing line, the template call stack and the data types.
+// `Render` calls it on `Reload` mode, so it is only needed
+// when the templates are rendered through `ExecuteTemplate`.
+//
+// The page exposes the templates source so, when `Reload` is off,
+// it responds with a plain "Internal Server Error" instead.
+func (v *Blocks) RenderError(w http.ResponseWriter, r *http.Request, err error) {
+	if !v.reload {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	var te *TemplateError
+	if !errors.As(err, &te) {
+		te = v.templateError(err)
+	}
+
+	b := v.bufferPool.Get()
+	defer v.bufferPool.Put(b)
+
+	if execErr := errorPageTemplate.Execute(b, te); execErr != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Inject the registered snippets, e.g. the live reload script,
+	// so the page is refreshed when the error is fixed.
+	var snippets strings.Builder
+	for _, inject := range v.injectors {
+		snippets.WriteString(inject(r.Context(), "", "", nil))
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	header.Set("Cache-Control", "no-store")
+	header.Del("Content-Length")
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write(injectBeforeBody(b.Bytes(), snippets.String()))
+}
+
+var errorPageTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Template Error</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { color: #c00; font-size: 1.4em; }
+pre.message { background: #fff5f5; border-left: 4px solid #c00; padding: 1em; white-space: pre-wrap; }
+table { border-collapse: collapse; }
+table.source { font-family: monospace; white-space: pre; width: 100%; background: #fafafa; }
+table.source td { padding: 0 .5em; }
+table.source td.num { color: #888; text-align: right; user-select: none; }
+table.source tr.current { background: #ffebe9; font-weight: bold; }
+table.stack th, table.stack td { text-align: left; padding: .3em .8em; border-bottom: 1px solid #eee; }
+code { font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>Template Error</h1>
+<pre class="message">{{ .Err }}</pre>
+{{- if .Filename }}
+<h2>{{ .Filename }}{{ if .Line }}:{{ .Line }}{{ if .Column }}:{{ .Column }}{{ end }}{{ end }}</h2>
+{{- end }}
+{{- if .Source }}
+<table class="source">
+{{- range .Source }}
+<tr{{ if .Current }} class="current"{{ end }}><td class="num">{{ .Number }}</td><td>{{ .Text }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- if .Stack }}
+<h2>Template Stack</h2>
+<table class="stack">
+<tr><th>#</th><th>Kind</th><th>Name</th><th>File</th><th>Data Type</th></tr>
+{{- range $i, $frame := .Stack }}
+<tr><td>{{ $i }}</td><td>{{ $frame.Kind }}</td><td>{{ $frame.Name }}</td><td>{{ $frame.Filename }}</td><td><code>{{ $frame.DataType }}</code></td></tr>
+{{- end }}
+</table>
+{{- end }}
+</body>
+</html>
+`))
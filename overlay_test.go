@@ -0,0 +1,96 @@
+package blocks_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+type overlayPage struct {
+	Title string
+}
+
+func TestRenderError(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<main>{{ yield . }}</main>`), nil)
+	mfs.ParseTemplate("index.html", []byte("<h1>{{ .Title }}</h1>\n{{ partial \"partials/card\" .Title }}"), nil)
+	mfs.ParseTemplate("partials/card.html", []byte("<div>\n{{ .Missing }}\n</div>"), nil)
+
+	views := blocks.New(mfs).Reload(true)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	err := views.Render(rec, req, "index", "main", overlayPage{Title: "Home"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var te *blocks.TemplateError
+	if !errors.As(err, &te) {
+		t.Fatalf("expected a *TemplateError but got %T: %v", err, err)
+	}
+
+	if te.Filename != "partials/card.html" || te.Line != 2 {
+		t.Fatalf("expected the error at partials/card.html:2 but got %s:%d", te.Filename, te.Line)
+	}
+
+	expectedStack := []blocks.StackFrame{
+		{Kind: "layout", Name: "main", Filename: "layouts/main.html", DataType: "blocks_test.overlayPage"},
+		{Kind: "template", Name: "index", Filename: "index.html", DataType: "blocks_test.overlayPage"},
+		{Kind: "partial", Name: "partials/card", Filename: "partials/card.html", DataType: "string"},
+	}
+	if len(te.Stack) != len(expectedStack) {
+		t.Fatalf("expected stack:\n%v\nbut got:\n%v", expectedStack, te.Stack)
+	}
+	for i, frame := range expectedStack {
+		if te.Stack[i] != frame {
+			t.Fatalf("[%d] expected frame:\n%v\nbut got:\n%v", i, frame, te.Stack[i])
+		}
+	}
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status code %d but got %d", http.StatusInternalServerError, rec.Code)
+	}
+
+	body := rec.Body.String()
+	for _, expected := range []string{
+		`<h2>partials/card.html:2:3</h2>`,
+		`<tr class="current"><td class="num">2</td><td>{{ .Missing }}</td></tr>`,
+		`<td>partial</td><td>partials/card</td>`,
+		`<code>blocks_test.overlayPage</code>`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("expected body to contain:\n%s\nbut got:\n%s", expected, body)
+		}
+	}
+
+	if strings.Contains(body, "<main>") {
+		t.Fatalf("expected no partial page output but got:\n%s", body)
+	}
+
+	// Load errors.
+	mfs.ParseTemplate("index.html", []byte("<h1>\n{{ .Title </h1>"), nil)
+
+	rec = httptest.NewRecorder()
+	err = views.Render(rec, req, "index", "main", nil)
+	if !errors.As(err, &te) {
+		t.Fatalf("expected a *TemplateError but got %T: %v", err, err)
+	}
+
+	if te.Filename != "index.html" || te.Line != 2 {
+		t.Fatalf("expected the error at index.html:2 but got %s:%d", te.Filename, te.Line)
+	}
+
+	// The page is not shown on production.
+	views.Reload(false)
+
+	rec = httptest.NewRecorder()
+	views.RenderError(rec, req, err)
+	if body = rec.Body.String(); strings.Contains(body, "index.html") {
+		t.Fatalf("expected a plain error but got:\n%s", body)
+	}
+}
@@ -0,0 +1,269 @@
+package blocks
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Paginator is a page of a paginated list of entries, see `Collection.Paginate`.
+// It is the data of the list page routes, see `PageRoutes`.
+//
+// Usage:
+//
+//	{{ range .Items }}<a href="/posts/{{ .Slug }}">{{ .Title }}</a>{{ end }}
+//	{{ with .PrevURL }}<a href="{{ . }}">Newer</a>{{ end }}
+//	{{ range .Pages }}<a href="{{ .URL }}"{{ if .Current }} aria-current="page"{{ end }}>{{ .Number }}</a>{{ end }}
+//	{{ with .NextURL }}<a href="{{ . }}">Older</a>{{ end }}
+type Paginator struct {
+	// Items are the entries of the page.
+	Items Collection
+	// Number is the page number, starting from 1.
+	Number int
+	// PerPage is the maximum number of items of a page.
+	PerPage int
+	// TotalPages and TotalItems are the number of pages and entries of the whole list.
+	TotalPages, TotalItems int
+	// URL is the path of the page, e.g. "/tags/go/" for the first one and "/tags/go/page/2/" for the rest.
+	URL string
+	// PrevURL and NextURL are the paths of the previous and next pages, empty if there is not any.
+	PrevURL, NextURL string
+	// FirstURL and LastURL are the paths of the first and last pages.
+	FirstURL, LastURL string
+	// Term is the taxonomy term of the list, nil if it is not a term's list, see `Term.Paginate`.
+	Term *Term `json:",omitempty"`
+
+	urls []string
+}
+
+// PageLink is a link of a paginator's page numbers, see `Paginator.Pages`.
+type PageLink struct {
+	Number  int
+	URL     string
+	Current bool
+}
+
+// Pages returns the links of all pages of the list.
+func (p *Paginator) Pages() []PageLink {
+	links := make([]PageLink, 0, len(p.urls))
+	for i, url := range p.urls {
+		links = append(links, PageLink{Number: i + 1, URL: url, Current: i+1 == p.Number})
+	}
+
+	return links
+}
+
+// Paginate splits the entries to pages of "perPage" items, all of them to a single page if "perPage" is not positive.
+// The first page's URL is the "basePath" and the rest ones' is "basePath/page/{number}/".
+// An empty collection still has a single empty page.
+func (c Collection) Paginate(basePath string, perPage int) []*Paginator {
+	if perPage <= 0 {
+		perPage = max(len(c), 1)
+	}
+
+	base := strings.TrimSuffix(path.Clean("/"+basePath), "/") + "/"
+	total := max((len(c)+perPage-1)/perPage, 1)
+
+	urls := make([]string, total)
+	for i := range urls {
+		urls[i] = base
+		if i > 0 {
+			urls[i] += "page/" + strconv.Itoa(i+1) + "/"
+		}
+	}
+
+	pages := make([]*Paginator, total)
+	for i := range pages {
+		start := min(i*perPage, len(c))
+		end := min(start+perPage, len(c))
+
+		p := &Paginator{
+			Items:      c[start:end],
+			Number:     i + 1,
+			PerPage:    perPage,
+			TotalPages: total,
+			TotalItems: len(c),
+			URL:        urls[i],
+			FirstURL:   urls[0],
+			LastURL:    urls[total-1],
+			urls:       urls,
+		}
+		if i > 0 {
+			p.PrevURL = urls[i-1]
+		}
+		if i < total-1 {
+			p.NextURL = urls[i+1]
+		}
+
+		pages[i] = p
+	}
+
+	return pages
+}
+
+// Term is a value of a taxonomy, e.g. the "go" tag, and its entries, see `Collection.Taxonomy`.
+type Term struct {
+	// Taxonomy is the front matter key of the term, e.g. "tags".
+	Taxonomy string
+	// Name is the front matter value, e.g. "Go".
+	Name string
+	// Slug is the URL segment of the name, e.g. "go".
+	Slug string
+	// Entries are the entries of the term, in the collection's order.
+	Entries Collection `json:"-"`
+}
+
+// Taxonomy groups the entries by the values of the "key" front matter, e.g. "tags" or "category".
+// An entry belongs to all values of a list. The values are compared case-insensitively,
+// e.g. "Go" and "go" are the same term. The terms are sorted by their name.
+//
+// The slugs are unique: a term whose slug is taken by a term before it gets a "-2" (or "-3"...) suffix,
+// e.g. "C" is "c" and "C++" is "c-2". The values without letters or digits are skipped.
+func (c Collection) Taxonomy(key string) []*Term {
+	terms := make(map[string]*Term)
+	for _, entry := range c {
+		for _, name := range entryTerms(entry, key) {
+			termKey := strings.ToLower(strings.Join(strings.Fields(name), " "))
+			if slugify(termKey) == "" {
+				continue
+			}
+
+			term, ok := terms[termKey]
+			if !ok {
+				term = &Term{Taxonomy: key, Name: name}
+				terms[termKey] = term
+			}
+
+			if n := len(term.Entries); n > 0 && term.Entries[n-1] == entry {
+				continue // e.g. tags: [Go, go].
+			}
+			term.Entries = append(term.Entries, entry)
+		}
+	}
+
+	list := make([]*Term, 0, len(terms))
+	for _, term := range terms {
+		list = append(list, term)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	slugs := make(map[string]struct{}, len(list))
+	for _, term := range list {
+		base := slugify(term.Name)
+		slug := base
+		for n := 2; ; n++ {
+			if _, taken := slugs[slug]; !taken {
+				break
+			}
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		term.Slug = slug
+		slugs[slug] = struct{}{}
+	}
+
+	return list
+}
+
+// Paginate splits the term's entries to pages under the "basePath/{slug}/" path,
+// e.g. "/tags/go/" and "/tags/go/page/2/", see `Collection.Paginate`.
+func (t *Term) Paginate(basePath string, perPage int) []*Paginator {
+	pages := t.Entries.Paginate(path.Join("/", basePath, t.Slug), perPage)
+	for _, p := range pages {
+		p.Term = t
+	}
+
+	return pages
+}
+
+// entryTerms returns the values of the "key" front matter of the "entry".
+func entryTerms(entry *Entry, key string) []string {
+	if key == "tags" {
+		return entry.Tags
+	}
+
+	switch value := entry.FrontMatter[key].(type) {
+	case nil:
+		return nil
+	case []any:
+		names := make([]string, 0, len(value))
+		for _, item := range value {
+			names = append(names, fmt.Sprint(item))
+		}
+		return names
+	default:
+		return []string{fmt.Sprint(value)}
+	}
+}
+
+// slugify returns the lower case letters and digits of the "name", separated by dashes, e.g. "Web Dev" to "web-dev".
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+
+		dash = true
+	}
+
+	return b.String()
+}
+
+// PageRoutes returns the static site routes of the "pages", rendered with the "tmplName" and "layoutName"
+// and the `*Paginator` of each page as their data.
+// Their LastMod is the newest one of their items, e.g.
+//
+//	site.Route(blocks.PageRoutes(posts.Paginate("/blog", 10), "blog", "main")...)
+//	for _, term := range posts.Taxonomy("tags") {
+//	  site.Route(blocks.PageRoutes(term.Paginate("/tags", 10), "tag", "main")...)
+//	}
+func PageRoutes(pages []*Paginator, tmplName, layoutName string) []Route {
+	routes := make([]Route, 0, len(pages))
+	for _, p := range pages {
+		route := Route{
+			Path:     p.URL,
+			Template: tmplName,
+			Layout:   layoutName,
+			Data:     func(context.Context) (any, error) { return p, nil },
+		}
+		for _, entry := range p.Items {
+			if lastMod := entry.LastMod(); lastMod.After(route.LastMod) {
+				route.LastMod = lastMod
+			}
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes
+}
+
+// EntryRoutes returns the static site routes of the entries' pages, rendered with the "tmplName" and "layoutName"
+// and the `*Entry` as their data. The "entryPath" returns the path of an entry's page, e.g.
+//
+//	site.Route(blocks.EntryRoutes(posts, func(e *blocks.Entry) string { return "/posts/" + e.Slug }, "post", "main")...)
+func EntryRoutes(entries Collection, entryPath func(*Entry) string, tmplName, layoutName string) []Route {
+	routes := make([]Route, 0, len(entries))
+	for _, entry := range entries {
+		routes = append(routes, Route{
+			Path:     entryPath(entry),
+			Template: tmplName,
+			Layout:   layoutName,
+			Data:     func(context.Context) (any, error) { return entry, nil },
+			LastMod:  entry.LastMod(),
+		})
+	}
+
+	return routes
+}
@@ -0,0 +1,83 @@
+package blocks_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestPagination(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("tag.html", []byte(`{{ .Term.Name }} {{ .Number }}/{{ .TotalPages }}:{{ range .Items }} {{ .Slug }}{{ end }}`+
+		`{{ with .PrevURL }} prev={{ . }}{{ end }}{{ with .NextURL }} next={{ . }}{{ end }} [{{ range .Pages }}{{ if .Current }}*{{ end }}{{ .URL }};{{ end }}]`), nil)
+	for i := 1; i <= 5; i++ {
+		tags := "[Go]"
+		if i%2 == 0 {
+			tags = "[Go, Web Dev]"
+		}
+		mfs.ParseTemplate(fmt.Sprintf("posts/post%d.md", i), []byte(fmt.Sprintf("---\ndate: 2024-01-0%d\ntags: %s\n---\nPost %d.", i, tags, i)), nil)
+	}
+
+	views := blocks.New(mfs).Collection("posts", "posts")
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+	posts := views.Entries("posts")
+
+	pages := posts.Paginate("/blog", 2)
+	if len(pages) != 3 || pages[0].URL != "/blog/" || pages[2].URL != "/blog/page/3/" || len(pages[2].Items) != 1 || pages[1].PrevURL != "/blog/" || pages[2].NextURL != "" {
+		t.Fatalf("unexpected pages: %#v", pages)
+	}
+	if pages := blocks.Collection(nil).Paginate("/", 10); len(pages) != 1 || pages[0].URL != "/" || pages[0].TotalPages != 1 {
+		t.Fatalf("expected a single empty page but got: %#v", pages)
+	}
+
+	terms := posts.Taxonomy("tags")
+	if len(terms) != 2 || terms[0].Slug != "go" || len(terms[0].Entries) != 5 || terms[1].Slug != "web-dev" || len(terms[1].Entries) != 2 {
+		t.Fatalf("unexpected terms: %#v", terms)
+	}
+
+	langs := blocks.Collection{
+		{Slug: "a", FrontMatter: map[string]any{"lang": []any{"C", "c", "???"}}},
+		{Slug: "b", FrontMatter: map[string]any{"lang": []any{"C++", "c-2"}}},
+		{Slug: "c", FrontMatter: map[string]any{"lang": " "}},
+	}
+	var got []string
+	for _, term := range langs.Taxonomy("lang") {
+		got = append(got, fmt.Sprintf("%s=%s:%d", term.Name, term.Slug, len(term.Entries)))
+	}
+	if expected := "C=c:1 C++=c-2:1 c-2=c-2-2:1"; strings.Join(got, " ") != expected {
+		t.Fatalf("expected the terms:\n%s\nbut got:\n%s", expected, strings.Join(got, " "))
+	}
+
+	outputDir := t.TempDir()
+	site := blocks.NewSite(views)
+	site.OutputDir = outputDir
+	for _, term := range terms {
+		site.Route(blocks.PageRoutes(term.Paginate("/tags", 2), "tag", "")...)
+	}
+	if _, err := site.Build(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"tags/go/index.html":        `Go 1/3: post5 post4 next=/tags/go/page/2/ [*/tags/go/;/tags/go/page/2/;/tags/go/page/3/;]`,
+		"tags/go/page/2/index.html": `Go 2/3: post3 post2 prev=/tags/go/ next=/tags/go/page/3/ [/tags/go/;*/tags/go/page/2/;/tags/go/page/3/;]`,
+		"tags/web-dev/index.html":   `Web Dev 1/1: post4 post2 [*/tags/web-dev/;]`,
+	}
+	for file, contents := range expected {
+		data, err := os.ReadFile(filepath.Join(outputDir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := string(data); got != contents {
+			t.Fatalf("%s: expected:\n%s\nbut got:\n%s", file, contents, got)
+		}
+	}
+}
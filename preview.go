@@ -0,0 +1,269 @@
+package blocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DefaultFixturesSuffix is the default suffix of the fixtures files,
+// which live next to their template file, e.g. "index.fixtures.json" for "index.html".
+const DefaultFixturesSuffix = ".fixtures.json"
+
+// noLayout is the "layout" query value of the preview's render page
+// to render a template without a layout.
+const noLayout = "-"
+
+// Preview is an http.Handler which lists the engine's templates and layouts
+// and renders any template and layout pair with fixture data,
+// so the pages and partials can be previewed without running the application.
+//
+// The fixtures of a template are loaded from a JSON file next to it,
+// e.g. "index.fixtures.json" for "index.html", which holds one or more named scenarios:
+//
+//	{
+//	  "default": { "Title": "Home" },
+//	  "logged-in": { "Title": "Home", "User": { "Name": "kataras" } }
+//	}
+//
+// The fixtures are read on each request. To reload the templates
+// on file changes use the engine's `Watch` method.
+//
+// Usage:
+//
+//	go views.Watch(ctx, 0, nil)
+//	http.Handle("/preview/", http.StripPrefix("/preview", blocks.NewPreview(views)))
+//
+// See the "blocks serve" command too.
+type Preview struct {
+	v *Blocks
+	// FixturesSuffix defaults to `DefaultFixturesSuffix`.
+	FixturesSuffix string
+}
+
+var _ http.Handler = (*Preview)(nil)
+
+// NewPreview returns a new Preview handler of the "v" loaded engine.
+func NewPreview(v *Blocks) *Preview {
+	return &Preview{v: v, FixturesSuffix: DefaultFixturesSuffix}
+}
+
+// ServeHTTP implements the http.Handler interface.
+// It serves the templates list on its root path
+// and a rendered template on its "render" path, based on the
+// "template", "layout" ("-" for none) and "scenario" query parameters.
+func (p *Preview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch strings.Trim(r.URL.Path, "/") {
+	case "":
+		p.serveIndex(w, r)
+	case "render":
+		p.serveRender(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Fixtures returns the fixture scenarios of the "tmplName" template
+// and their sorted names, the "default" scenario comes first.
+// A template without a fixtures file has a single "default" scenario of nil data.
+func (p *Preview) Fixtures(tmplName string) (map[string]any, []string, error) {
+	scenarios := make(map[string]any)
+
+	b, err := fs.ReadFile(p.v.fs, p.fixturesFilename(tmplName))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, err
+		}
+
+		scenarios["default"] = nil
+		return scenarios, []string{"default"}, nil
+	}
+
+	if err = json.Unmarshal(b, &scenarios); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", p.fixturesFilename(tmplName), err)
+	}
+
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "default" || names[j] == "default" {
+			return names[i] == "default"
+		}
+		return names[i] < names[j]
+	})
+
+	return scenarios, names, nil
+}
+
+func (p *Preview) fixturesFilename(tmplName string) string {
+	suffix := p.FixturesSuffix
+	if suffix == "" {
+		suffix = DefaultFixturesSuffix
+	}
+
+	filename := tmplName + p.v.extension
+	p.v.mu.RLock()
+	for _, file := range p.v.files {
+		if !file.layout && file.name == tmplName {
+			filename = file.filename
+			break
+		}
+	}
+	p.v.mu.RUnlock()
+
+	if idx := strings.LastIndexByte(filename, '.'); idx > strings.LastIndexByte(filename, '/') {
+		filename = filename[:idx]
+	}
+
+	return filename + suffix
+}
+
+type previewTemplate struct {
+	Name      string
+	Layout    string // the layout it's rendered with by default.
+	Scenarios []string
+	Err       error
+}
+
+type previewIndex struct {
+	Templates []previewTemplate
+	Layouts   []string
+	NoLayout  string
+}
+
+func (p *Preview) serveIndex(w http.ResponseWriter, r *http.Request) {
+	index := previewIndex{Layouts: p.v.LayoutNames(), NoLayout: noLayout}
+	for _, name := range p.v.TemplateNames() {
+		_, scenarios, err := p.Fixtures(name)
+		_, layout := p.v.resolveNames(r.Context(), name, "")
+		index.Templates = append(index.Templates, previewTemplate{
+			Name:      name,
+			Layout:    layout,
+			Scenarios: scenarios,
+			Err:       err,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := previewIndexTemplate.Execute(w, index); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (p *Preview) serveRender(w http.ResponseWriter, r *http.Request) {
+	var (
+		query      = r.URL.Query()
+		tmplName   = query.Get("template")
+		layoutName = query.Get("layout")
+		scenario   = query.Get("scenario")
+	)
+
+	scenarios, names, err := p.Fixtures(tmplName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if scenario == "" {
+		scenario = names[0]
+	}
+
+	data, ok := scenarios[scenario]
+	if !ok {
+		http.Error(w, fmt.Sprintf("scenario %q of template %q does not exist", scenario, tmplName), http.StatusNotFound)
+		return
+	}
+
+	b := p.v.bufferPool.Get()
+	defer p.v.bufferPool.Put(b)
+
+	if layoutName == noLayout {
+		err = p.renderWithoutLayout(r.Context(), b, tmplName, data)
+	} else {
+		err = p.v.ExecuteTemplateContext(r.Context(), b, tmplName, layoutName, data)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(b.Bytes())
+}
+
+// renderWithoutLayout is the `Blocks.ExecuteTemplateContext` of the "tmplName" template
+// without any layout, even its front matter or the default one.
+func (p *Preview) renderWithoutLayout(ctx context.Context, w io.Writer, tmplName string, data any) error {
+	if p.v.reload {
+		if err := p.v.Load(); err != nil {
+			return p.v.renderError(ctx, err, tmplName, "", data)
+		}
+	}
+
+	tmplName, _ = p.v.resolveNames(ctx, tmplName, "")
+	return p.v.renderResolved(ctx, w, tmplName, "", data)
+}
+
+var previewIndexTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Templates Preview</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: .4em .8em; border-bottom: 1px solid #eee; }
+.error { color: #c00; }
+</style>
+</head>
+<body>
+<h1>Templates</h1>
+<table>
+<tr><th>Template</th><th>Scenarios</th><th>Render with</th></tr>
+{{- range .Templates }}
+<tr>
+<td>{{ .Name }}</td>
+<td>
+{{- if .Err }}<span class="error">{{ .Err }}</span>{{ end }}
+{{- $tmpl := . }}
+{{- range .Scenarios }} <a href="render?template={{ $tmpl.Name }}&amp;scenario={{ . }}">{{ . }}</a>{{ end }}
+</td>
+<td>
+<form action="render">
+<input type="hidden" name="template" value="{{ .Name }}">
+<select name="layout">
+<option value="{{ $.NoLayout }}"{{ if not .Layout }} selected{{ end }}>(no layout)</option>
+{{- range $.Layouts }}
+<option value="{{ . }}"{{ if eq . $tmpl.Layout }} selected{{ end }}>{{ . }}</option>
+{{- end }}
+</select>
+<select name="scenario">
+{{- range .Scenarios }}
+<option value="{{ . }}">{{ . }}</option>
+{{- end }}
+</select>
+<button type="submit">Render</button>
+</form>
+</td>
+</tr>
+{{- end }}
+</table>
+<h2>Layouts</h2>
+<ul>
+{{- range .Layouts }}
+<li>{{ . }}</li>
+{{- end }}
+</ul>
+</body>
+</html>
+`))
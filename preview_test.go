@@ -0,0 +1,119 @@
+package blocks_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/blocks"
+)
+
+func TestPreview(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<main>{{ yield . }}</main>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>{{ .Title }}</h1>{{ if .User }}<p>{{ .User.Name }}</p>{{ end }}`), nil)
+	mfs.ParseTemplate("index.fixtures.json", []byte(`{
+		"default": {"Title": "Home"},
+		"logged-in": {"Title": "Home", "User": {"Name": "kataras"}}
+	}`), nil)
+
+	views := blocks.New(mfs).DefaultLayout("main")
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(blocks.NewPreview(views))
+	defer srv.Close()
+
+	tests := []struct {
+		path     string
+		code     int
+		contains string
+	}{
+		{"/", http.StatusOK, `<a href="render?template=index&amp;scenario=logged-in">logged-in</a>`},
+		{"/render?template=index", http.StatusOK, `<main><h1>Home</h1></main>`},
+		{"/render?template=index&layout=-&scenario=logged-in", http.StatusOK, `<h1>Home</h1><p>kataras</p>`},
+		{"/render?template=index&scenario=missing", http.StatusNotFound, `scenario "missing" of template "index" does not exist`},
+	}
+
+	for _, tt := range tests {
+		resp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body bytes.Buffer
+		_, err = body.ReadFrom(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.code || !strings.Contains(body.String(), tt.contains) {
+			t.Errorf("%s: expected status %d and body to contain:\n%s\nbut got %d:\n%s", tt.path, tt.code, tt.contains, resp.StatusCode, body.String())
+		}
+	}
+}
+
+func TestPreviewWithoutLayoutSandbox(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<main>{{ yield . }}</main>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`{{ partial "card" . }}{{ partial "card" . }}`), nil)
+	mfs.ParseTemplate("card.html", []byte(`<card>`), nil)
+
+	views := blocks.New(mfs).DefaultLayout("main").Sandbox(blocks.Sandbox{Funcs: []string{"partial"}, MaxPartials: 1})
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	blocks.NewPreview(views).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/render?template=index&layout=-", nil))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "render exceeds 1 partials") {
+		t.Fatalf("expected the sandbox to limit the partials but got %d:\n%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWatch(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("index.html", []byte(`v1`), nil)
+
+	views := blocks.New(mfs)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan []string, 1)
+	go views.Watch(ctx, 10*time.Millisecond, func(changed []string, err error) {
+		if err != nil {
+			t.Error(err)
+		}
+		reloaded <- changed
+	})
+
+	time.Sleep(30 * time.Millisecond)
+	mfs.ParseTemplate("index.html", []byte(`version 2`), nil)
+
+	select {
+	case changed := <-reloaded:
+		if len(changed) != 1 || changed[0] != "index.html" {
+			t.Fatalf("expected index.html to be changed but got: %v", changed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for reload")
+	}
+
+	got, err := views.TemplateString("index", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "version 2" {
+		t.Fatalf("expected the reloaded template but got: %s", got)
+	}
+}
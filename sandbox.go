@@ -0,0 +1,218 @@
+package blocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"text/template/parse"
+	"time"
+)
+
+// Sandbox holds the restrictions of the sandbox mode, see `Blocks.Sandbox`.
+type Sandbox struct {
+	// Funcs is the allowlist of the functions the templates may call,
+	// including the engine's "partial" one.
+	// The predefined functions of the text/template package, e.g. "eq" and "printf", are always allowed.
+	Funcs []string
+	// PartialPrefixes, if not empty, restricts the templates the "partial" function
+	// may render to the ones with any of these name prefixes, e.g. "public/".
+	PartialPrefixes []string
+	// MaxOutput, if positive, limits the output size of a render, in bytes.
+	MaxOutput int64
+	// Timeout, if positive, limits the wall-clock time of a render.
+	// It is checked on each write of the output, each partial and each call
+	// of a function registered through `ContextFuncs`, and a render which
+	// exceeds it fails even if it wrote nothing after it.
+	// A function which blocks, without checking its context, is not interrupted:
+	// the render fails after it returns.
+	Timeout time.Duration
+	// MaxPartials, if positive, limits the number of the partials of a render.
+	MaxPartials int
+}
+
+// Sandbox turns on the sandbox mode, for untrusted templates,
+// e.g. customized by tenants through a `MemoryFileSystem`.
+// On `Load`, a template which calls a function out of the "s.Funcs" allowlist fails,
+// and on each render the partials and the limits of the "s" are enforced.
+// A violation is reported as a `*SandboxError`.
+// It should be called before the engine is loaded.
+func (v *Blocks) Sandbox(s Sandbox) *Blocks {
+	v.sandbox = &s
+	return v
+}
+
+// SandboxRule is a rule of the sandbox mode, see `SandboxError`.
+type SandboxRule string
+
+// The sandbox mode rules.
+const (
+	// SandboxFunc is violated by a call of a function out of the `Sandbox.Funcs`.
+	SandboxFunc SandboxRule = "func"
+	// SandboxPartial is violated by a partial out of the `Sandbox.PartialPrefixes`.
+	SandboxPartial SandboxRule = "partial"
+	// SandboxOutput is violated by a render larger than the `Sandbox.MaxOutput`.
+	SandboxOutput SandboxRule = "output"
+	// SandboxTimeout is violated by a render slower than the `Sandbox.Timeout`.
+	SandboxTimeout SandboxRule = "timeout"
+	// SandboxPartials is violated by a render of more partials than the `Sandbox.MaxPartials`.
+	SandboxPartials SandboxRule = "partials"
+)
+
+// SandboxError reports a violation of the sandbox mode.
+// A `SandboxTimeout` error matches the context.DeadlineExceeded through errors.Is.
+type SandboxError struct {
+	Rule SandboxRule
+	// Name is the function or the partial name of the `SandboxFunc` and `SandboxPartial` rules.
+	Name string
+	// Location is the "file:line:col" of the `SandboxFunc` rule's call.
+	Location string
+	// Limit is the exceeded limit of the `SandboxOutput` (bytes),
+	// `SandboxTimeout` (nanoseconds) and `SandboxPartials` rules.
+	Limit int64
+}
+
+// Error implements the `error` interface.
+func (e *SandboxError) Error() string {
+	switch e.Rule {
+	case SandboxFunc:
+		return fmt.Sprintf("blocks: sandbox: %s: function %q is not allowed", e.Location, e.Name)
+	case SandboxPartial:
+		return fmt.Sprintf("blocks: sandbox: partial %q is not allowed", e.Name)
+	case SandboxOutput:
+		return fmt.Sprintf("blocks: sandbox: output exceeds %d bytes", e.Limit)
+	case SandboxTimeout:
+		return fmt.Sprintf("blocks: sandbox: render exceeds %s", time.Duration(e.Limit))
+	case SandboxPartials:
+		return fmt.Sprintf("blocks: sandbox: render exceeds %d partials", e.Limit)
+	default:
+		return fmt.Sprintf("blocks: sandbox: %s", e.Rule)
+	}
+}
+
+// Is reports whether the "target" is context.DeadlineExceeded for the `SandboxTimeout` rule.
+func (e *SandboxError) Is(target error) bool {
+	return e.Rule == SandboxTimeout && target == context.DeadlineExceeded
+}
+
+// checkFuncs returns a `SandboxError` for the first call
+// of a function out of the allowlist by the "files".
+func (s *Sandbox) checkFuncs(files []*templateFile) error {
+	allowed := make(map[string]struct{}, len(s.Funcs)+len(textTemplateBuiltins))
+	for _, names := range [][]string{s.Funcs, textTemplateBuiltins} {
+		for _, name := range names {
+			allowed[name] = struct{}{}
+		}
+	}
+
+	for _, file := range files {
+		trees, err := file.parseTrees()
+		if err != nil {
+			return mapError(err, files)
+		}
+
+		var violation *SandboxError
+		for _, tree := range trees {
+			walkNodes(tree.Root, func(node parse.Node) {
+				ident, ok := node.(*parse.IdentifierNode)
+				if !ok || violation != nil {
+					return
+				}
+
+				if _, ok = allowed[ident.Ident]; !ok {
+					line, col := file.position(int(ident.Position()))
+					violation = &SandboxError{
+						Rule:     SandboxFunc,
+						Name:     ident.Ident,
+						Location: fmt.Sprintf("%s:%d:%d", file.filename, line, col),
+					}
+				}
+			})
+		}
+
+		if violation != nil {
+			return violation
+		}
+	}
+
+	return nil
+}
+
+// sandboxRender is the state of a render on the sandbox mode.
+type sandboxRender struct {
+	partials atomic.Int64
+}
+
+type sandboxContextKey struct{}
+
+// sandboxContext returns the "ctx" of a new render on the sandbox mode,
+// which holds the render's state and its `Sandbox.Timeout` deadline.
+// A partial's render shares the state of the render it is called from.
+func (v *Blocks) sandboxContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if v.sandbox == nil || ctx.Value(sandboxContextKey{}) != nil {
+		return ctx, func() {}
+	}
+
+	ctx = context.WithValue(ctx, sandboxContextKey{}, new(sandboxRender))
+	if timeout := v.sandbox.Timeout; timeout > 0 {
+		return context.WithTimeoutCause(ctx, timeout, &SandboxError{Rule: SandboxTimeout, Limit: int64(timeout)})
+	}
+
+	return ctx, func() {}
+}
+
+// checkPartial returns a `SandboxError` if the "partialName" partial
+// can not be rendered from the render of the "ctx".
+func (s *Sandbox) checkPartial(ctx context.Context, partialName string) error {
+	if len(s.PartialPrefixes) > 0 {
+		allowed := false
+		for _, prefix := range s.PartialPrefixes {
+			if strings.HasPrefix(partialName, prefix) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return &SandboxError{Rule: SandboxPartial, Name: partialName}
+		}
+	}
+
+	if render, ok := ctx.Value(sandboxContextKey{}).(*sandboxRender); ok && s.MaxPartials > 0 {
+		if render.partials.Add(1) > int64(s.MaxPartials) {
+			return &SandboxError{Rule: SandboxPartials, Limit: int64(s.MaxPartials)}
+		}
+	}
+
+	return nil
+}
+
+// contextErr returns the `SandboxError` which canceled the "ctx", if any, otherwise its error.
+func contextErr(ctx context.Context) error {
+	var sandboxErr *SandboxError
+	if errors.As(context.Cause(ctx), &sandboxErr) {
+		return sandboxErr
+	}
+
+	return ctx.Err()
+}
+
+// limitWriter fails with a `SandboxOutput` error
+// when more than "limit" bytes are written to it.
+type limitWriter struct {
+	w     io.Writer
+	n     int64
+	limit int64
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if w.n+int64(len(p)) > w.limit {
+		return 0, &SandboxError{Rule: SandboxOutput, Limit: w.limit}
+	}
+
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,120 @@
+package blocks_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/blocks"
+)
+
+func TestSandbox(t *testing.T) {
+	newViews := func(s blocks.Sandbox, files map[string]string) *blocks.Blocks {
+		mfs := blocks.NewMemoryFileSystem()
+		for name, contents := range files {
+			mfs.ParseTemplate(name, []byte(contents), nil)
+		}
+
+		return blocks.New(mfs).Funcs(map[string]any{
+			"upper": strings.ToUpper,
+			"env":   func(string) string { return "secret" },
+			"sleep": func() string { time.Sleep(50 * time.Millisecond); return "" },
+		}).Sandbox(s)
+	}
+
+	// Functions allowlist.
+	views := newViews(blocks.Sandbox{Funcs: []string{"upper"}}, map[string]string{
+		"index.html": "<h1>{{ upper .Title }}</h1>\n{{ if eq .Title \"x\" }}{{ env \"KEY\" }}{{ end }}",
+	})
+	err := views.Load()
+	var sandboxErr *blocks.SandboxError
+	if !errors.As(err, &sandboxErr) || sandboxErr.Rule != blocks.SandboxFunc || sandboxErr.Name != "env" || sandboxErr.Location != "index.html:2:25" {
+		t.Fatalf("expected a func sandbox error at index.html:2:25 but got %T: %v", err, err)
+	}
+
+	files := map[string]string{
+		"index.html":        `{{ partial "public/card" . }}`,
+		"private.html":      `{{ partial "admin/secret" . }}`,
+		"many.html":         `{{ range .Items }}{{ partial "public/card" . }}{{ end }}`,
+		"big.html":          `{{ range .Items }}0123456789{{ end }}`,
+		"slow.html":         `{{ range .Items }}{{ sleep }}.{{ end }}`,
+		"public/card.html":  `<card>`,
+		"admin/secret.html": `<secret>`,
+	}
+	views = newViews(blocks.Sandbox{
+		Funcs:           []string{"partial", "sleep"},
+		PartialPrefixes: []string{"public/"},
+		MaxOutput:       50,
+		Timeout:         20 * time.Millisecond,
+		MaxPartials:     3,
+	}, files)
+	if err = views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if contents, err := views.TemplateString("index", "", nil); err != nil || contents != "<card>" {
+		t.Fatalf("expected the public partial but got %q: %v", contents, err)
+	}
+
+	tests := []struct {
+		tmpl  string
+		items int
+		rule  blocks.SandboxRule
+	}{
+		{"private", 0, blocks.SandboxPartial},
+		{"many", 4, blocks.SandboxPartials},
+		{"big", 6, blocks.SandboxOutput},
+		{"slow", 3, blocks.SandboxTimeout},
+	}
+
+	for _, tt := range tests {
+		data := map[string]any{"Items": make([]int, tt.items)}
+		err := views.ExecuteTemplateContext(context.Background(), new(strings.Builder), tt.tmpl, "", data)
+		if !errors.As(err, &sandboxErr) || sandboxErr.Rule != tt.rule {
+			t.Fatalf("%s: expected a %s sandbox error but got %T: %v", tt.tmpl, tt.rule, err, err)
+		}
+	}
+
+	if !errors.Is(sandboxErr, context.DeadlineExceeded) {
+		t.Fatalf("expected the timeout error to be a context.DeadlineExceeded")
+	}
+
+	// The limits are per render.
+	data := map[string]any{"Items": make([]int, 3)}
+	for i := 0; i < 2; i++ {
+		if err = views.ExecuteTemplate(new(strings.Builder), "many", "", data); err != nil {
+			t.Fatalf("[%d] expected no error but got: %v", i, err)
+		}
+	}
+}
+
+func TestSandboxTimeoutWithoutOutput(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("funcs.html", []byte(`{{ range .Items }}{{ $x := slow }}{{ end }}done`), nil)
+	mfs.ParseTemplate("partials.html", []byte(`{{ range .Items }}{{ $x := partial "quiet" . }}{{ end }}done`), nil)
+	mfs.ParseTemplate("quiet.html", []byte(`{{ $x := sleep }}`), nil)
+
+	views := blocks.New(mfs).
+		Funcs(map[string]any{"sleep": func() string { time.Sleep(10 * time.Millisecond); return "" }}).
+		ContextFuncs(map[string]any{"slow": func(context.Context) string { time.Sleep(10 * time.Millisecond); return "" }}).
+		Sandbox(blocks.Sandbox{Funcs: []string{"partial", "sleep", "slow"}, Timeout: 15 * time.Millisecond})
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]any{"Items": make([]int, 20)}
+	for _, tmpl := range []string{"funcs", "partials"} {
+		start := time.Now()
+		err := views.ExecuteTemplate(new(strings.Builder), tmpl, "", data)
+		var sandboxErr *blocks.SandboxError
+		if !errors.As(err, &sandboxErr) || sandboxErr.Rule != blocks.SandboxTimeout {
+			t.Fatalf("%s: expected a timeout sandbox error but got %T: %v", tmpl, err, err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Fatalf("%s: expected the render to stop on its timeout but it took %s", tmpl, elapsed)
+		}
+	}
+}
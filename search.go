@@ -0,0 +1,266 @@
+package blocks
+
+import (
+	"encoding/json"
+	"html"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SearchDocument is a page of the search index, see `Site.SearchIndex`.
+type SearchDocument struct {
+	URL      string   `json:"url"`
+	Title    string   `json:"title,omitempty"`
+	Headings []string `json:"headings,omitempty"`
+	// Text is the visible text of the page, its whitespace collapsed.
+	Text string `json:"text"`
+}
+
+// searchIndex returns the JSON search index of the HTML "pages" written to the "outputDir".
+func (s *Site) searchIndex(outputDir string, pages []BuildPage) ([]byte, error) {
+	docs := make([]SearchDocument, 0, len(pages))
+	for _, page := range pages {
+		if page.Err != nil || path.Ext(page.File) != ".html" {
+			continue
+		}
+		if s.NotFound.Template != "" && page.File == "404.html" {
+			continue
+		}
+
+		contents, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(page.File)))
+		if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, extractSearchDocument(page.Path, string(contents)))
+	}
+
+	return json.Marshal(docs)
+}
+
+// searchSkippedTags are the elements whose text is not indexed,
+// elements with a "data-search-ignore" attribute are skipped too.
+var searchSkippedTags = map[string]bool{
+	"script": true, "style": true, "nav": true, "noscript": true, "template": true, "svg": true,
+}
+
+// voidTags are the elements without a closing tag.
+var voidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// extractSearchDocument returns the title, the headings and the visible text of the "page" HTML.
+func extractSearchDocument(url, page string) SearchDocument {
+	doc := SearchDocument{URL: url}
+
+	var (
+		title, text strings.Builder
+		heading     *strings.Builder // the text of the current heading, if any.
+		inTitle     bool
+		skip        string // the name of the skipped element, if any.
+		depth       int    // the nesting of the skipped element.
+	)
+	for s := page; len(s) > 0; {
+		i := strings.IndexByte(s, '<')
+		if i == -1 {
+			i = len(s)
+		}
+
+		if chunk := s[:i]; chunk != "" && skip == "" {
+			chunk = html.UnescapeString(chunk)
+			if inTitle {
+				title.WriteString(chunk)
+			} else {
+				text.WriteString(chunk)
+				if heading != nil {
+					heading.WriteString(chunk)
+				}
+			}
+		}
+		s = s[i:]
+
+		if strings.HasPrefix(s, "<!--") {
+			end := strings.Index(s, "-->")
+			if end == -1 {
+				break
+			}
+			s = s[end+len("-->"):]
+			continue
+		}
+
+		end := strings.IndexByte(s, '>')
+		if end == -1 {
+			break
+		}
+		tag := s[1:end]
+		s = s[end+1:]
+
+		closing := strings.HasPrefix(tag, "/")
+		name := tagName(tag)
+		selfClosing := voidTags[name] || strings.HasSuffix(tag, "/")
+
+		if skip != "" {
+			if name == skip && !selfClosing {
+				if closing {
+					depth--
+				} else {
+					depth++
+				}
+				if depth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		if !closing && (searchSkippedTags[name] || strings.Contains(tag, "data-search-ignore")) {
+			if name == "script" || name == "style" {
+				// Raw text, it may contain '<' characters.
+				if end := strings.Index(strings.ToLower(s), "</"+name); end != -1 {
+					s = s[end:]
+					if end = strings.IndexByte(s, '>'); end != -1 {
+						s = s[end+1:]
+						continue
+					}
+				}
+				break
+			}
+
+			if !selfClosing {
+				skip, depth = name, 1
+			}
+			continue
+		}
+
+		switch name {
+		case "title":
+			inTitle = !closing
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			if !closing {
+				heading = new(strings.Builder)
+			} else if heading != nil {
+				if h := collapseSpace(heading.String()); h != "" {
+					doc.Headings = append(doc.Headings, h)
+				}
+				heading = nil
+			}
+		}
+
+		text.WriteByte(' ') // separate the text of adjacent elements.
+	}
+
+	doc.Title = collapseSpace(title.String())
+	doc.Text = collapseSpace(text.String())
+	return doc
+}
+
+// tagName returns the lower case name of a tag's "<...>" contents, e.g. "div" of `/div` or `div class="x"`.
+func tagName(tag string) string {
+	tag = strings.TrimPrefix(tag, "/")
+	end := strings.IndexAny(tag, " \t\r\n/")
+	if end == -1 {
+		end = len(tag)
+	}
+
+	return strings.ToLower(tag[:end])
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// SearchPartial registers a built-in "search" content template,
+// unless the file system has one, which renders a search box of a `Site.SearchIndex`,
+// e.g. {{ partial "search" "/search.json" }}. Its data is the URL of the index.
+// The index is fetched on the first search and its pages are ranked
+// by the matches of the terms in their title, headings and text.
+// It should be called before the engine is loaded.
+func (v *Blocks) SearchPartial(b bool) *Blocks {
+	v.searchPartial = b
+	return v
+}
+
+const searchPartialName = "search"
+
+// addSearchPartial adds the built-in "search" template to the "filesMap",
+// unless it has a template of the same name.
+func (v *Blocks) addSearchPartial(filesMap map[string][]byte) error {
+	for filename := range filesMap {
+		name := strings.TrimPrefix(trimDir(filename, v.rootDir), "/")
+		if strings.TrimSuffix(name, path.Ext(name)) == searchPartialName {
+			return nil
+		}
+	}
+
+	filename := searchPartialName + v.extension
+	left, right, err := v.delimsOf(filename, nil)
+	if err != nil {
+		return err
+	}
+
+	source := strings.NewReplacer("{{", left, "}}", right).Replace(searchPartialSource)
+	filesMap[filename] = []byte(source)
+	return nil
+}
+
+// searchPartialSource is the built-in "search" template, see `SearchPartial`.
+// Its "{{" and "}}" delimiters are replaced by the engine's ones.
+const searchPartialSource = `<div class="blocks-search" data-search-ignore>
+  <input type="search" placeholder="Search" aria-label="Search" autocomplete="off">
+  <ul></ul>
+</div>
+<script>
+(function () {
+  var root = document.currentScript.previousElementSibling;
+  var input = root.querySelector("input"), list = root.querySelector("ul");
+  var indexURL = {{ or . "/search.json" }}, index = null, loading = null;
+
+  function score(doc, terms) {
+    var title = (doc.title || "").toLowerCase(), headings = (doc.headings || []).join(" ").toLowerCase(), text = doc.text.toLowerCase(), n = 0;
+    for (var i = 0; i < terms.length; i++) {
+      var t = terms[i], s = 0;
+      if (title.indexOf(t) !== -1) s += 10;
+      if (headings.indexOf(t) !== -1) s += 5;
+      if (text.indexOf(t) !== -1) s += 1;
+      if (s === 0) return 0;
+      n += s;
+    }
+    return n;
+  }
+
+  function snippet(text, term) {
+    var i = Math.max(text.toLowerCase().indexOf(term) - 40, 0);
+    return (i > 0 ? "…" : "") + text.substr(i, 120) + "…";
+  }
+
+  function render() {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    list.textContent = "";
+    if (!index || terms.length === 0) return;
+
+    index.map(function (doc) { return [score(doc, terms), doc]; })
+      .filter(function (r) { return r[0] > 0; })
+      .sort(function (a, b) { return b[0] - a[0]; })
+      .slice(0, 10)
+      .forEach(function (r) {
+        var li = document.createElement("li"), a = document.createElement("a"), p = document.createElement("p");
+        a.href = r[1].url;
+        a.textContent = r[1].title || r[1].url;
+        p.textContent = snippet(r[1].text, terms[0]);
+        li.appendChild(a);
+        li.appendChild(p);
+        list.appendChild(li);
+      });
+  }
+
+  input.addEventListener("input", function () {
+    if (index) return render();
+    if (!loading) {
+      loading = fetch(indexURL).then(function (r) { return r.json(); }).then(function (docs) { index = docs; render(); });
+    }
+  });
+})();
+</script>`
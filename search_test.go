@@ -0,0 +1,67 @@
+package blocks_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestSearchIndex(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<html><head><title>{{ .Title }} | Docs</title><style>h1 { color: red }</style></head>`+
+		`<body><nav><a href="/">Home</a><nav><a href="/x">Nested</a></nav></nav>{{ partial "search" "/search.json" }}<main>{{ yield . }}</main>`+
+		`<script>if (a < b) { document.write("<p>hidden</p>") }</script></body></html>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>Getting <em>Started</em></h1><p>Install &amp; run.</p><!-- a comment --><h2>Next</h2><p>Read<br>more.</p>`), nil)
+	mfs.ParseTemplate("guide.html", []byte(`<h1>Guide</h1><div data-search-ignore><p>Ignored</p></div><p>Templates.</p>`), nil)
+	mfs.ParseTemplate("404.html", []byte(`<h1>Not Found</h1>`), nil)
+
+	views := blocks.New(mfs).SearchPartial(true)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	site := blocks.NewSite(views)
+	site.OutputDir = outputDir
+	site.SearchIndex = "/search.json"
+	site.NotFound = blocks.Route{Template: "404", Layout: "main"}
+	for _, route := range []blocks.Route{{Path: "/", Template: "index"}, {Path: "/guide", Template: "guide"}} {
+		title := route.Template
+		route.Layout = "main"
+		route.Data = func(context.Context) (any, error) { return map[string]any{"Title": title}, nil }
+		site.Route(route)
+	}
+
+	if _, err := site.Build(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "search.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var docs []blocks.SearchDocument
+	if err = json.Unmarshal(data, &docs); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []blocks.SearchDocument{
+		{URL: "/", Title: "index | Docs", Headings: []string{"Getting Started", "Next"}, Text: "Getting Started Install & run. Next Read more."},
+		{URL: "/guide", Title: "guide | Docs", Headings: []string{"Guide"}, Text: "Guide Templates."},
+	}
+	if !reflect.DeepEqual(docs, expected) {
+		t.Fatalf("expected:\n%#v\nbut got:\n%#v", expected, docs)
+	}
+
+	page, _ := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if !strings.Contains(string(page), `search.json", index = null`) {
+		t.Fatalf("expected the built-in search partial but got:\n%s", page)
+	}
+}
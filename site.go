@@ -0,0 +1,535 @@
+package blocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultOutputDir is the default output directory of a `Site`.
+const DefaultOutputDir = "./public"
+
+// Route is a page of a `Site`.
+type Route struct {
+	// Path is the URL path of the page, e.g. "/", "/about" or "/feed.xml".
+	// A path without an extension is written as a clean URL, to its "index.html" file,
+	// e.g. "/about" to "about/index.html", otherwise as it is.
+	Path string
+	// Template and Layout are the names to render the page with,
+	// see `ExecuteTemplateContext` for an empty Layout.
+	Template, Layout string
+	// Data, if not nil, loads the data of the page.
+	Data func(ctx context.Context) (any, error)
+	// LastMod, if not zero, is the last modification time of the page, see `Site.Sitemap`.
+	LastMod time.Time
+}
+
+// Site is a static site generator of the engine's templates.
+// Its routes are rendered concurrently to the `OutputDir`.
+//
+// Usage:
+//
+//	site := blocks.NewSite(views)
+//	site.Assets = os.DirFS("./assets")
+//	site.NotFound = blocks.Route{Template: "404", Layout: "main"}
+//	site.Route(blocks.Route{Path: "/", Template: "index", Layout: "main"})
+//	report, err := site.Build(ctx)
+//
+// See the "blocks build" command too.
+type Site struct {
+	v *Blocks
+	// OutputDir is the directory the site is written to, defaults to `DefaultOutputDir`.
+	OutputDir string
+	// Assets, if not nil, is the file system of the static files,
+	// e.g. stylesheets and images, which are copied as they are to the OutputDir.
+	Assets fs.FS
+	// NotFound, if its Template is not empty, is the page written to "404.html".
+	NotFound Route
+	// Concurrency is the maximum number of pages rendered in parallel,
+	// defaults to runtime.GOMAXPROCS(0).
+	Concurrency int
+	// Incremental, if true, re-renders only the pages whose inputs changed since the last build:
+	// their template, layout and partials files, their data, the engine's functions and the Version.
+	// The outputs of the pages and assets which no longer exist are removed.
+	// The inputs of each page are kept in the `ManifestFile`.
+	Incremental bool
+	// ManifestFile is the manifest of the incremental builds,
+	// defaults to the "<OutputDir>.manifest.json" file, next to the output directory.
+	ManifestFile string
+	// Version is an input of all pages on incremental builds, e.g. the application's version,
+	// so changes of the functions' code re-render all pages.
+	Version string
+	// SearchIndex, if not empty, is the path of the JSON search index of the HTML pages, e.g. "/search.json",
+	// written after the pages are rendered. Each page's title, headings and visible text are indexed,
+	// the text of <script>, <style> and <nav> elements and of elements with a "data-search-ignore" attribute is skipped.
+	// See `Blocks.SearchPartial` for a search box of the index.
+	SearchIndex string
+
+	routes []Route
+	files  []siteFile
+}
+
+// siteFile is a generated file of the site, see `Site.File`.
+type siteFile struct {
+	path     string
+	contents func() ([]byte, error)
+}
+
+// NewSite returns a new static site generator of the "v" engine.
+func NewSite(v *Blocks) *Site {
+	return &Site{v: v, OutputDir: DefaultOutputDir}
+}
+
+// Route adds one or more pages to the site.
+func (s *Site) Route(routes ...Route) *Site {
+	s.routes = append(s.routes, routes...)
+	return s
+}
+
+// File adds a generated file to the site, e.g. a feed or the sitemap,
+// written to the "filePath" after the pages are rendered, so its "contents" can depend on them.
+//
+//	site.File("/feed.xml", feed.RSS)
+//	site.File("/sitemap.xml", func() ([]byte, error) { return site.Sitemap(baseURL).XML() })
+func (s *Site) File(filePath string, contents func() ([]byte, error)) *Site {
+	s.files = append(s.files, siteFile{path: filePath, contents: contents})
+	return s
+}
+
+// Routes returns the pages of the site, including the NotFound one.
+func (s *Site) Routes() []Route {
+	routes := make([]Route, 0, len(s.routes)+1)
+	routes = append(routes, s.routes...)
+	if s.NotFound.Template != "" {
+		notFound := s.NotFound
+		notFound.Path = "/404.html"
+		routes = append(routes, notFound)
+	}
+
+	return routes
+}
+
+// BuildReport is the result of a `Site.Build`.
+type BuildReport struct {
+	// Pages are sorted by their path, the generated files are included.
+	Pages []BuildPage
+	// Assets are the copied static files, relative to the output directory.
+	Assets []string
+	// Removed are the stale files of an incremental build, relative to the output directory.
+	Removed  []string
+	Duration time.Duration
+}
+
+// BuildPage is the result of a single page of a `Site.Build`.
+type BuildPage struct {
+	Path string
+	// File is the written file, relative to the output directory, e.g. "about/index.html".
+	File             string
+	Template, Layout string
+	Bytes            int
+	Duration         time.Duration
+	// Unchanged reports whether the page was not re-rendered
+	// because its inputs did not change, see `Site.Incremental`.
+	Unchanged bool
+	// Err is the error of the page's data or render, if any.
+	Err error
+}
+
+// WriteText writes a summary of the build to "w",
+// one line per page and a total one.
+func (r *BuildReport) WriteText(w io.Writer) error {
+	var failed, unchanged, bytes int
+	for _, page := range r.Pages {
+		if page.Err != nil {
+			failed++
+			if _, err := fmt.Fprintf(w, "%s\tFAIL\t%v\n", page.Path, page.Err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		bytes += page.Bytes
+		if page.Unchanged {
+			unchanged++
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%d bytes\tunchanged\n", page.Path, page.File, page.Bytes); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%d bytes\t%s\n", page.Path, page.File, page.Bytes, page.Duration.Round(time.Microsecond)); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range r.Removed {
+		if _, err := fmt.Fprintf(w, "removed\t%s\n", file); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d pages (%d unchanged, %d failed, %d bytes), %d assets in %s\n", len(r.Pages), unchanged, failed, bytes, len(r.Assets), r.Duration.Round(time.Millisecond))
+	return err
+}
+
+// Build renders the routes of the site to their files under the `OutputDir`,
+// creating the directories as needed, writes the generated files, see `File`, and copies the `Assets`.
+// The pages are rendered concurrently, a failed page does not stop the rest.
+// See `Incremental` to re-render only the changed pages.
+//
+// It returns the build report and the errors of the failed pages, joined.
+// The engine must be loaded, on `Reload` mode it is loaded once per build.
+func (s *Site) Build(ctx context.Context) (*BuildReport, error) {
+	start := time.Now()
+
+	if s.v.reload {
+		if err := s.v.LoadWithContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	routes := s.Routes()
+	pages := make([]BuildPage, len(routes))
+	paths := make(map[string]string, len(routes)) // file -> route path.
+	for i, route := range routes {
+		if route.Template == "" {
+			return nil, fmt.Errorf("blocks: build: %s: missing template", route.Path)
+		}
+
+		file, err := routeFile(route.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if other, exists := paths[file]; exists {
+			return nil, fmt.Errorf("blocks: build: the routes %q and %q are both written to %s", other, route.Path, file)
+		}
+		paths[file] = route.Path
+
+		pages[i] = BuildPage{Path: route.Path, File: file, Template: route.Template, Layout: route.Layout}
+	}
+
+	outputDir := s.OutputDir
+	if outputDir == "" {
+		outputDir = DefaultOutputDir
+	}
+
+	siteFiles := s.files
+	if s.SearchIndex != "" {
+		siteFiles = append(slices.Clone(siteFiles), siteFile{path: s.SearchIndex, contents: func() ([]byte, error) {
+			return s.searchIndex(outputDir, pages[:len(routes)])
+		}})
+	}
+
+	files := make([]BuildPage, len(siteFiles))
+	for i, f := range siteFiles {
+		file, err := routeFile(f.path)
+		if err != nil {
+			return nil, err
+		}
+
+		if other, exists := paths[file]; exists {
+			return nil, fmt.Errorf("blocks: build: the routes %q and %q are both written to %s", other, f.path, file)
+		}
+		paths[file] = f.path
+
+		files[i] = BuildPage{Path: f.path, File: file}
+	}
+
+	var (
+		manifest, lastManifest *buildManifest
+		inputs                 []map[string]string
+	)
+	if s.Incremental {
+		var err error
+		if lastManifest, err = readManifest(s.manifestFile(outputDir)); err != nil {
+			return nil, err
+		}
+		manifest = &buildManifest{Pages: make(map[string]manifestPage, len(routes))}
+
+		funcsDigest := s.v.funcsDigest(s.Version)
+		inputs = make([]map[string]string, len(routes))
+		for i, route := range routes {
+			inputs[i] = s.v.pageInputs(route.Template, route.Layout, funcsDigest)
+			if len(s.v.sharedData) > 0 {
+				inputs[i]["shared"] = dataDigest(s.v.sharedData)
+			}
+		}
+	}
+
+	concurrency := s.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+	for i := range routes {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(i int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				page := &pages[i]
+				pageStart := time.Now()
+				if !s.Incremental {
+					data, err := loadRouteData(ctx, routes[i])
+					if err == nil {
+						page.Bytes, err = s.buildPage(ctx, outputDir, page.File, routes[i], data)
+					}
+					page.Err = err
+					page.Duration = time.Since(pageStart)
+					return
+				}
+
+				var last *manifestPage
+				if p, ok := lastManifest.Pages[page.File]; ok {
+					last = &p
+				}
+
+				page.Unchanged, page.Bytes, page.Err = s.buildChangedPage(ctx, outputDir, page.File, routes[i], inputs[i], last)
+				page.Duration = time.Since(pageStart)
+			}(i)
+		}
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// The generated files are written after the pages, see `File`.
+	for i, f := range siteFiles {
+		page := &files[i]
+		fileStart := time.Now()
+
+		var last *manifestPage
+		if s.Incremental {
+			if p, ok := lastManifest.Pages[page.File]; ok {
+				last = &p
+			}
+		}
+
+		var fileInputs map[string]string
+		fileInputs, page.Unchanged, page.Bytes, page.Err = s.buildFile(outputDir, page.File, f, last)
+		page.Duration = time.Since(fileStart)
+
+		pages = append(pages, *page)
+		if s.Incremental {
+			inputs = append(inputs, fileInputs)
+		}
+	}
+
+	report := &BuildReport{Pages: pages}
+
+	if s.Assets != nil {
+		assets, err := copyAssets(ctx, s.Assets, outputDir)
+		if err != nil {
+			return nil, err
+		}
+		report.Assets = assets
+	}
+
+	if s.Incremental {
+		for i, page := range pages {
+			if page.Err == nil {
+				manifest.Pages[page.File] = manifestPage{Path: page.Path, Inputs: inputs[i], Bytes: page.Bytes}
+			}
+		}
+		manifest.Assets = report.Assets
+
+		// Remove the outputs of the last build which no longer exist.
+		current := make(map[string]struct{}, len(paths)+len(report.Assets))
+		for file := range paths {
+			current[file] = struct{}{}
+		}
+		for _, file := range report.Assets {
+			current[file] = struct{}{}
+		}
+
+		var stale []string
+		for file := range lastManifest.Pages {
+			stale = append(stale, file)
+		}
+		stale = append(stale, lastManifest.Assets...)
+		sort.Strings(stale)
+
+		for _, file := range stale {
+			if _, exists := current[file]; exists {
+				continue
+			}
+
+			if err := removeStale(outputDir, file); err != nil {
+				return nil, err
+			}
+			report.Removed = append(report.Removed, file)
+			current[file] = struct{}{} // remove each file once.
+		}
+
+		if err := manifest.write(s.manifestFile(outputDir)); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Path < pages[j].Path
+	})
+	report.Duration = time.Since(start)
+
+	var errs []error
+	for _, page := range pages {
+		if page.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", page.Path, page.Err))
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// buildChangedPage renders the "route" like `buildPage` does,
+// unless its "inputs" are the same as the "last" build ones and its file still exists.
+func (s *Site) buildChangedPage(ctx context.Context, outputDir, file string, route Route, inputs map[string]string, last *manifestPage) (bool, int, error) {
+	data, err := loadRouteData(ctx, route)
+	if err != nil {
+		return false, 0, err
+	}
+	inputs["data"] = dataDigest(data)
+
+	if last != nil && maps.Equal(last.Inputs, inputs) {
+		if _, err = os.Stat(filepath.Join(outputDir, filepath.FromSlash(file))); err == nil {
+			return true, last.Bytes, nil
+		}
+	}
+
+	n, err := s.buildPage(ctx, outputDir, file, route, data)
+	return false, n, err
+}
+
+// buildFile writes the generated "f" file to the "file" of the "outputDir",
+// unless its contents are the same as the "last" build ones and it still exists.
+// It returns the inputs of the file, its digest, for incremental builds.
+func (s *Site) buildFile(outputDir, file string, f siteFile, last *manifestPage) (map[string]string, bool, int, error) {
+	data, err := f.contents()
+	if err != nil {
+		return nil, false, 0, err
+	}
+
+	inputs := map[string]string{"contents": digest(data)}
+	if last != nil && maps.Equal(last.Inputs, inputs) {
+		if _, err = os.Stat(filepath.Join(outputDir, filepath.FromSlash(file))); err == nil {
+			return inputs, true, len(data), nil
+		}
+	}
+
+	if err = writeFile(filepath.Join(outputDir, filepath.FromSlash(file)), data); err != nil {
+		return nil, false, 0, err
+	}
+
+	return inputs, false, len(data), nil
+}
+
+// buildPage renders the "route" with its "data" to the "file" of the "outputDir"
+// and returns the written bytes.
+func (s *Site) buildPage(ctx context.Context, outputDir, file string, route Route, data any) (int, error) {
+	b := s.v.bufferPool.Get()
+	defer s.v.bufferPool.Put(b)
+
+	if err := s.v.render(ctx, b, route.Template, route.Layout, data); err != nil {
+		return 0, err
+	}
+
+	if err := writeFile(filepath.Join(outputDir, filepath.FromSlash(file)), b.Bytes()); err != nil {
+		return 0, err
+	}
+
+	return b.Len(), nil
+}
+
+func loadRouteData(ctx context.Context, route Route) (any, error) {
+	if route.Data == nil {
+		return nil, nil
+	}
+
+	data, err := route.Data(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("data: %w", err)
+	}
+
+	return data, nil
+}
+
+// routeFile returns the file of a route's path, relative to the output directory.
+// The path is cleaned as a rooted one, so it can not escape the output directory.
+func routeFile(routePath string) (string, error) {
+	if routePath == "" {
+		return "", fmt.Errorf("blocks: build: invalid route path %q", routePath)
+	}
+
+	p := strings.TrimPrefix(path.Clean("/"+routePath), "/")
+	if path.Ext(p) == "" {
+		p = path.Join(p, "index.html")
+	}
+
+	return p, nil
+}
+
+// copyAssets copies the regular files of the "assets" to the "outputDir"
+// and returns their sorted names.
+func copyAssets(ctx context.Context, assets fs.FS, outputDir string) ([]string, error) {
+	var names []string
+	err := fs.WalkDir(assets, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		data, err := fs.ReadFile(assets, name)
+		if err != nil {
+			return err
+		}
+
+		if err = writeFile(filepath.Join(outputDir, filepath.FromSlash(name)), data); err != nil {
+			return err
+		}
+
+		names = append(names, name)
+		return nil
+	})
+
+	sort.Strings(names)
+	return names, err
+}
+
+func writeFile(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
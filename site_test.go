@@ -0,0 +1,169 @@
+package blocks_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kataras/blocks"
+)
+
+func TestSiteBuild(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<main>{{ yield . }}</main>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>{{ .Title }}</h1>`), nil)
+	mfs.ParseTemplate("about.html", []byte(`<h1>About</h1>`), nil)
+	mfs.ParseTemplate("404.html", []byte(`<h1>Not Found</h1>`), nil)
+
+	views := blocks.New(mfs)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	site := blocks.NewSite(views)
+	site.OutputDir = outputDir
+	site.Assets = fstest.MapFS{"css/site.css": {Data: []byte("body {}")}}
+	site.NotFound = blocks.Route{Template: "404", Layout: "main"}
+	site.Route(
+		blocks.Route{Path: "/", Template: "index", Layout: "main", Data: func(context.Context) (any, error) {
+			return map[string]any{"Title": "Home"}, nil
+		}},
+		blocks.Route{Path: "/about", Template: "about", Layout: "main"},
+		blocks.Route{Path: "/feed.xml", Template: "about"},
+	)
+
+	report, err := site.Build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"index.html":       `<main><h1>Home</h1></main>`,
+		"about/index.html": `<main><h1>About</h1></main>`,
+		"feed.xml":         `<h1>About</h1>`,
+		"404.html":         `<main><h1>Not Found</h1></main>`,
+		"css/site.css":     `body {}`,
+	}
+	for file, contents := range expected {
+		data, err := os.ReadFile(filepath.Join(outputDir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := string(data); got != contents {
+			t.Fatalf("%s: expected:\n%s\nbut got:\n%s", file, contents, got)
+		}
+	}
+
+	if len(report.Pages) != 4 || report.Pages[0].Path != "/" || report.Pages[1].File != "404.html" || len(report.Assets) != 1 {
+		t.Fatalf("unexpected report: %#v", report)
+	}
+
+	// A failed page does not stop the rest.
+	site.Route(blocks.Route{Path: "/broken", Template: "missing"}, blocks.Route{Path: "/data", Template: "index", Data: func(context.Context) (any, error) {
+		return nil, errors.New("no data")
+	}})
+	report, err = site.Build(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "/broken: template 'missing' does not exist") || !strings.Contains(err.Error(), "/data: data: no data") {
+		t.Fatalf("expected the page errors but got: %v", err)
+	}
+	if len(report.Pages) != 6 {
+		t.Fatalf("expected 6 pages but got %d", len(report.Pages))
+	}
+
+	site.Route(blocks.Route{Path: "/about/", Template: "about"})
+	if _, err = site.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "both written to about/index.html") {
+		t.Fatalf("expected a duplicated route error but got: %v", err)
+	}
+}
+
+func TestSiteIncrementalBuild(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<main>{{ yield . }}</main>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>{{ .Title }}</h1>{{ partial "footer" . }}`), nil)
+	mfs.ParseTemplate("about.html", []byte(`<h1>About</h1>`), nil)
+	mfs.ParseTemplate("footer.html", []byte(`<footer>v1</footer>`), nil)
+
+	views := blocks.New(mfs)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(t.TempDir(), "public")
+	title := "Home"
+	newSite := func(routes ...blocks.Route) *blocks.Site {
+		site := blocks.NewSite(views)
+		site.OutputDir = outputDir
+		site.Incremental = true
+		return site.Route(routes...)
+	}
+	index := blocks.Route{Path: "/", Template: "index", Layout: "main", Data: func(context.Context) (any, error) {
+		return map[string]any{"Title": title}, nil
+	}}
+	about := blocks.Route{Path: "/about", Template: "about", Layout: "main"}
+
+	build := func(site *blocks.Site, expectedUnchanged ...string) *blocks.BuildReport {
+		t.Helper()
+
+		report, err := site.Build(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var unchanged []string
+		for _, page := range report.Pages {
+			if page.Unchanged {
+				unchanged = append(unchanged, page.Path)
+			}
+		}
+		if strings.Join(unchanged, ",") != strings.Join(expectedUnchanged, ",") {
+			t.Fatalf("expected unchanged pages %v but got %v", expectedUnchanged, unchanged)
+		}
+
+		return report
+	}
+
+	build(newSite(index, about))
+	build(newSite(index, about), "/", "/about")
+
+	if _, err := os.Stat(outputDir + ".manifest.json"); err != nil {
+		t.Fatalf("expected the manifest next to the output directory: %v", err)
+	}
+
+	// A partial changed.
+	mfs.ParseTemplate("footer.html", []byte(`<footer>v2</footer>`), nil)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+	build(newSite(index, about), "/about")
+
+	if data, _ := os.ReadFile(filepath.Join(outputDir, "index.html")); string(data) != `<main><h1>Home</h1><footer>v2</footer></main>` {
+		t.Fatalf("unexpected index page: %s", data)
+	}
+
+	// The data changed.
+	title = "Welcome"
+	build(newSite(index, about), "/about")
+
+	// The version changed.
+	site := newSite(index, about)
+	site.Version = "2"
+	build(site)
+
+	// A page removed.
+	site = newSite(index)
+	site.Version = "2"
+	report := build(site, "/")
+	if len(report.Removed) != 1 || report.Removed[0] != "about/index.html" {
+		t.Fatalf("expected the about page to be removed but got %v", report.Removed)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "about")); !os.IsNotExist(err) {
+		t.Fatalf("expected the empty about directory to be removed: %v", err)
+	}
+}
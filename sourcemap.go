@@ -0,0 +1,240 @@
+package blocks
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// sourceEdit replaces the [start, end) byte range of a transformation's input with the text.
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// sourceSegment maps the output offsets from "gen", up to the next segment's one,
+// to the input offsets from "orig". All offsets of a pinned segment,
+// i.e. of an inserted text, map to "orig".
+type sourceSegment struct {
+	gen, orig int
+	pinned    bool
+}
+
+// sourceMap maps the offsets of a template file's transformed contents,
+// e.g. after the front matter, comments and yield rewrites and the define content wrap,
+// back to its original source, through each transformation step.
+type sourceMap struct {
+	source string
+	steps  [][]sourceSegment
+}
+
+func newSourceMap(source string) *sourceMap {
+	return &sourceMap{source: source}
+}
+
+// edit applies the sorted, non-overlapping "edits" to the "input",
+// records the transformation step and returns its output.
+func (m *sourceMap) edit(input string, edits []sourceEdit) string {
+	if len(edits) == 0 {
+		return input
+	}
+
+	var (
+		b        strings.Builder
+		segments = make([]sourceSegment, 0, 2*len(edits)+1)
+		in       int
+	)
+	for _, e := range edits {
+		segments = append(segments, sourceSegment{gen: b.Len(), orig: in})
+		b.WriteString(input[in:e.start])
+		if e.text != "" {
+			segments = append(segments, sourceSegment{gen: b.Len(), orig: e.start, pinned: true})
+			b.WriteString(e.text)
+		}
+		in = e.end
+	}
+	segments = append(segments, sourceSegment{gen: b.Len(), orig: in})
+	b.WriteString(input[in:])
+
+	m.steps = append(m.steps, segments)
+	return b.String()
+}
+
+// anchor records the step of an opaque transformation from "input" to "output",
+// e.g. markdown to HTML. The template actions, which such transformations keep as they are,
+// are mapped exactly and the rest of the output to the end of the preceding action.
+func (m *sourceMap) anchor(input, output, left, right string) {
+	var (
+		segments = []sourceSegment{{gen: 0, orig: 0, pinned: true}}
+		cursor   int // the input offset after the last anchored action.
+	)
+	for gen := 0; ; {
+		start := strings.Index(output[gen:], left)
+		if start == -1 {
+			break
+		}
+		start += gen
+
+		end := strings.Index(output[start+len(left):], right)
+		if end == -1 {
+			break
+		}
+		end += start + len(left) + len(right)
+
+		action := output[start:end]
+		if idx := strings.Index(input[cursor:], action); idx != -1 {
+			orig := cursor + idx
+			segments = append(segments,
+				sourceSegment{gen: start, orig: orig},
+				sourceSegment{gen: end, orig: orig + len(action), pinned: true})
+			cursor = orig + len(action)
+		}
+
+		gen = end
+	}
+
+	m.steps = append(m.steps, segments)
+}
+
+// offset returns the source offset of the "pos" offset of the transformed contents.
+func (m *sourceMap) offset(pos int) int {
+	for i := len(m.steps) - 1; i >= 0; i-- {
+		segments := m.steps[i]
+		idx := sort.Search(len(segments), func(j int) bool { return segments[j].gen > pos }) - 1
+		if idx < 0 {
+			continue
+		}
+
+		seg := segments[idx]
+		if seg.pinned {
+			pos = seg.orig
+		} else {
+			pos = seg.orig + pos - seg.gen
+		}
+	}
+
+	return min(max(pos, 0), len(m.source))
+}
+
+// position returns the 1-based line and the 0-based byte column, as text/template reports them,
+// of the source offset of the "pos" offset of the transformed contents.
+func (m *sourceMap) position(pos int) (line, col int) {
+	pos = m.offset(pos)
+	line = 1 + strings.Count(m.source[:pos], "\n")
+	col = pos - (strings.LastIndexByte(m.source[:pos], '\n') + 1)
+	return
+}
+
+// regexpEdits returns the edits which replace each match of "re" in "input"
+// with the "replacement" template, see `regexp.Regexp.Expand`.
+func regexpEdits(re *regexp.Regexp, input, replacement string) []sourceEdit {
+	var edits []sourceEdit
+	for _, match := range re.FindAllStringSubmatchIndex(input, -1) {
+		text := string(re.ExpandString(nil, replacement, input, match))
+		edits = append(edits, sourceEdit{start: match[0], end: match[1], text: text})
+	}
+
+	return edits
+}
+
+// trimSpaceEdits returns the edits which trim the leading and trailing white space of "input".
+func trimSpaceEdits(input string) []sourceEdit {
+	start := len(input) - len(strings.TrimLeftFunc(input, unicode.IsSpace))
+	end := len(strings.TrimRightFunc(input, unicode.IsSpace))
+	if start >= end {
+		if len(input) == 0 {
+			return nil
+		}
+
+		return []sourceEdit{{start: 0, end: len(input)}}
+	}
+
+	var edits []sourceEdit
+	if start > 0 {
+		edits = append(edits, sourceEdit{start: 0, end: start})
+	}
+	if end < len(input) {
+		edits = append(edits, sourceEdit{start: end, end: len(input)})
+	}
+
+	return edits
+}
+
+// position returns the source line and column of the "pos" offset of the file's contents.
+func (f *templateFile) position(pos int) (line, col int) {
+	return f.smap.position(pos)
+}
+
+// edit applies the "edits" to the file's contents.
+func (f *templateFile) edit(edits ...sourceEdit) {
+	f.contents = f.smap.edit(f.contents, edits)
+}
+
+// errorPrefixRegex matches the location a text/template or html/template error starts with,
+// e.g. "template: index.html:2:9:", "template: index.html:2:" and "html/template:index.html:2:9:".
+var errorPrefixRegex = regexp.MustCompile(`^((?:html/)?template: ?)([^:]+):(\d+)(?::(\d+))?:`)
+
+// sourceError is an error with its location rewritten to the source file.
+type sourceError struct {
+	err error
+	msg string
+}
+
+func (e *sourceError) Error() string { return e.msg }
+func (e *sourceError) Unwrap() error { return e.err }
+
+// mapError returns "err" with the template location its message starts with
+// rewritten from the transformed contents of the "files" to their source file and line/column.
+// Content templates are parsed under their file name and layouts under their name.
+func mapError(err error, files []*templateFile) error {
+	if _, ok := err.(*sourceError); ok || err == nil {
+		return err // already mapped.
+	}
+
+	msg := err.Error()
+	match := errorPrefixRegex.FindStringSubmatchIndex(msg)
+	if match == nil {
+		return err
+	}
+
+	name := msg[match[4]:match[5]]
+	var file *templateFile
+	for _, f := range files {
+		if f.filename == name || (f.layout && f.name == name) {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return err
+	}
+
+	line, _ := strconv.Atoi(msg[match[6]:match[7]])
+	hasCol := match[8] != -1
+	col := 0
+	if hasCol {
+		col, _ = strconv.Atoi(msg[match[8]:match[9]])
+	}
+
+	// The offset of the line and column on the transformed contents.
+	pos := 0
+	for n := 1; n < line; n++ {
+		idx := strings.IndexByte(file.contents[pos:], '\n')
+		if idx == -1 {
+			pos = len(file.contents)
+			break
+		}
+		pos += idx + 1
+	}
+	pos = min(pos+col, len(file.contents))
+
+	line, col = file.position(pos)
+	location := msg[match[2]:match[3]] + file.filename + ":" + strconv.Itoa(line)
+	if hasCol {
+		location += ":" + strconv.Itoa(col)
+	}
+
+	return &sourceError{err: err, msg: location + ":" + msg[match[1]:]}
+}
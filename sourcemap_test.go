@@ -0,0 +1,105 @@
+package blocks_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+
+	"github.com/kataras/blocks"
+)
+
+func TestSourceMapErrors(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<html>
+<!-- a
+multiline comment -->
+<body>{{ yield . }}</body>
+<footer>{{ .Footer.Missing }}</footer>
+</html>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`
+
+---
+title: Home
+---
+<!-- comment -->
+<h1>{{ .Title }}</h1>
+<p>{{ .User.Name }}</p>`), nil)
+	mfs.ParseTemplate("post.md", []byte(`# Post
+
+Some *text*.
+
+{{ .Post.Title }}`), nil)
+
+	views := blocks.New(mfs)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tmpl, layout string
+		data         any
+		prefix       string
+	}{
+		{"index", "", map[string]any{"Title": "Home", "User": 1}, `template: index.html:8:11: executing "content" at <.User.Name>`},
+		{"index", "main", map[string]any{"Title": "Home", "User": map[string]any{"Name": "kataras"}, "Footer": 1}, `template: layouts/main.html:5:18: executing "main" at <.Footer.Missing>`},
+		{"post.md", "", map[string]any{"Post": 1}, `template: post.md:5:8: executing "content" at <.Post.Title>`},
+	}
+
+	for _, tt := range tests {
+		_, err := views.TemplateString(tt.tmpl, tt.layout, tt.data)
+		if err == nil {
+			t.Fatalf("%s@%s: expected an error", tt.tmpl, tt.layout)
+		}
+
+		if !strings.HasPrefix(err.Error(), tt.prefix) {
+			t.Fatalf("%s@%s: expected error to start with:\n%s\nbut got:\n%s", tt.tmpl, tt.layout, tt.prefix, err)
+		}
+
+		var execErr texttemplate.ExecError
+		if !errors.As(err, &execErr) {
+			t.Fatalf("%s@%s: expected the original %T to be wrapped but got %T", tt.tmpl, tt.layout, execErr, err)
+		}
+	}
+
+	// Parse errors.
+	mfs.ParseTemplate("index.html", []byte("---\ntitle: Home\n---\n<h1>\n{{ .Title </h1>"), nil)
+	err := views.Load()
+	if expected := "template: index.html:5: "; err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Fatalf("expected error to start with:\n%s\nbut got:\n%v", expected, err)
+	}
+}
+
+func TestSourceMapCoverage(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("index.html", []byte(`---
+title: Home
+---
+<!-- comment -->
+{{ if .Show }}
+<p>shown</p>
+{{ end }}`), nil)
+
+	views := blocks.New(mfs).Coverage(true)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	report := views.CoverageReport()
+	if len(report.Files) != 1 {
+		t.Fatalf("expected a single file but got %d", len(report.Files))
+	}
+
+	file := report.Files[0]
+	if len(file.Blocks) != 2 || file.Blocks[1].Kind != "if" || file.Blocks[1].Line != 5 {
+		t.Fatalf("expected the if block on line 5 but got: %v", file.Blocks)
+	}
+
+	if _, ok := file.Lines[6]; !ok {
+		t.Fatalf("expected line 6 to be tracked but got: %v", file.Lines)
+	}
+
+	if !strings.HasPrefix(file.Source, "---\ntitle: Home") {
+		t.Fatalf("expected the original source but got:\n%s", file.Source)
+	}
+}
@@ -0,0 +1,71 @@
+package blocks_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestLayoutOptionsAndFuncs(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<title>{{ upper .Title }}</title>{{ .Missing }}{{ yield . }}`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>{{ .Title }}</h1>`), nil)
+
+	views := blocks.New(mfs).Option("missingkey=zero").Funcs(map[string]any{"upper": strings.ToUpper})
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := views.ExecuteTemplate(&b, "index", "main", map[string]any{"Title": "Home"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := `<title>HOME</title><h1>Home</h1>`, b.String(); got != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte("<main>\n{{ .Footer }}{{ yield . }}</main>"), nil)
+	mfs.ParseTemplate("index.html", []byte("<h1>{{ .Title }}</h1>\n<p>{{ .User.Name }}</p>"), nil)
+	mfs.ParseTemplate("footer.html", []byte(`<footer></footer>`), nil)
+
+	views := blocks.New(mfs).Strict(true)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		layout string
+		data   any
+		prefix string
+	}{
+		{"", nil, `template: index.html:1:7: executing "content" at <.Title>: nil data; no entry for key "Title"`},
+		{"", map[string]any{"Title": "Home"}, `template: index.html:2:11: executing "content" at <.User.Name>: map has no entry for key "User"`},
+		{"", map[string]any{"Title": "Home", "User": map[string]any(nil)}, `template: index.html:2:11: executing "content" at <.User.Name>: map has no entry for key "Name"`},
+		{"main", map[string]any{"Title": "Home", "User": map[string]any{"Name": "kataras"}}, `template: layouts/main.html:2:3: executing "main" at <.Footer>: map has no entry for key "Footer"`},
+	}
+
+	for i, tt := range tests {
+		err := views.ExecuteTemplate(new(strings.Builder), "index", tt.layout, tt.data)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.prefix) {
+			t.Fatalf("[%d] expected error to start with:\n%s\nbut got:\n%v", i, tt.prefix, err)
+		}
+	}
+
+	// Unknown partials.
+	mfs.ParseTemplate("index.html", []byte("<h1>{{ .Title }}</h1>\n{{ partial \"footr\" . }}"), nil)
+	err := views.Load()
+	if expected := `template: index.html:2:3: unknown partial: template 'footr' does not exist (did you mean 'footer'?)`; err == nil || err.Error() != expected {
+		t.Fatalf("expected error:\n%s\nbut got:\n%v", expected, err)
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected the unknown partial error to be a fs.ErrNotExist")
+	}
+}
@@ -0,0 +1,217 @@
+package blocks
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DevToolbar turns on the development toolbar, which is injected right before
+// the closing body tag of each page rendered with a layout. It shows the content template
+// and the layout of the page, each partial rendered with its timings,
+// the file system each file came from and a collapsible dump of the page's data.
+//
+// The toolbar is shown only on `Reload` mode, so it can not be left visible on production
+// by accident: it is a no-op as long as `Reload` is off.
+// It should be called before the engine is loaded.
+func (v *Blocks) DevToolbar(b bool) *Blocks {
+	if v.toolbar == nil {
+		if !b {
+			return v
+		}
+
+		v.toolbar = &toolbar{v: v, renders: make(map[uint64]*toolbarRender)}
+		v.Observe(v.toolbar)
+		v.injectors = append(v.injectors, v.toolbar.inject)
+	}
+
+	v.toolbar.enabled = b
+	return v
+}
+
+// toolbar is the `Observer` which collects the partials of each page
+// and the `injector` which renders the toolbar, see `DevToolbar`.
+type toolbar struct {
+	v       *Blocks
+	enabled bool
+
+	mu      sync.Mutex
+	renders map[uint64]*toolbarRender // by render ID.
+}
+
+var _ Observer = (*toolbar)(nil)
+
+// toolbarRender is a render in progress.
+type toolbarRender struct {
+	start    time.Time
+	partials []PartialEvent
+}
+
+func (t *toolbar) active() bool {
+	return t.enabled && t.v.reload
+}
+
+// LoadStart implements the `Observer` interface.
+func (t *toolbar) LoadStart(LoadStartEvent) {}
+
+// LoadFinish implements the `Observer` interface.
+func (t *toolbar) LoadFinish(LoadEvent) {}
+
+// RenderStart implements the `Observer` interface.
+func (t *toolbar) RenderStart(evt RenderStartEvent) {
+	if !t.active() {
+		return
+	}
+
+	t.mu.Lock()
+	t.renders[evt.ID] = &toolbarRender{start: evt.Start}
+	t.mu.Unlock()
+}
+
+// RenderFinish implements the `Observer` interface.
+func (t *toolbar) RenderFinish(evt RenderEvent) {
+	t.mu.Lock()
+	delete(t.renders, evt.ID)
+	t.mu.Unlock()
+}
+
+// PartialRender implements the `Observer` interface.
+func (t *toolbar) PartialRender(evt PartialEvent) {
+	t.mu.Lock()
+	if render, ok := t.renders[evt.RenderID]; ok {
+		render.partials = append(render.partials, evt)
+	}
+	t.mu.Unlock()
+}
+
+type toolbarFile struct {
+	Name     string
+	Filename string
+}
+
+type toolbarPartial struct {
+	toolbarFile
+	Duration time.Duration
+	Bytes    int64
+	Err      error
+}
+
+type toolbarView struct {
+	// FS describes the engine's file system, all the files come from it.
+	FS               string
+	Template, Layout toolbarFile
+	Partials         []toolbarPartial
+	Elapsed          time.Duration
+	DataType         string
+	Data             string
+}
+
+// inject implements the engine's injector, it returns the toolbar of the page.
+func (t *toolbar) inject(ctx context.Context, tmplName, layoutName string, data any) string {
+	if !t.active() || tmplName == "" {
+		return ""
+	}
+
+	view := toolbarView{
+		FS:       describeFS(t.v.fs),
+		Template: t.file(tmplName, false),
+		Layout:   t.file(layoutName, true),
+		DataType: fmt.Sprintf("%T", data),
+		Data:     dumpData(data),
+	}
+
+	if info := renderFromContext(ctx); info != nil {
+		t.mu.Lock()
+		if render, ok := t.renders[info.id]; ok {
+			view.Elapsed = time.Since(render.start)
+			for _, evt := range render.partials {
+				view.Partials = append(view.Partials, toolbarPartial{
+					toolbarFile: t.file(evt.Template, false),
+					Duration:    evt.Duration,
+					Bytes:       evt.Bytes,
+					Err:         evt.Err,
+				})
+			}
+		}
+		t.mu.Unlock()
+	}
+
+	var b strings.Builder
+	if err := toolbarTemplate.Execute(&b, view); err != nil {
+		return fmt.Sprintf("<!-- blocks: toolbar: %s -->", template.HTMLEscapeString(err.Error()))
+	}
+
+	return b.String()
+}
+
+// file returns the toolbar's description of the template (or layout) "name".
+func (t *toolbar) file(name string, layout bool) toolbarFile {
+	file := toolbarFile{Name: name}
+
+	t.v.mu.RLock()
+	for _, f := range t.v.files {
+		if f.layout == layout && f.name == name {
+			file.Filename = f.filename
+			break
+		}
+	}
+	t.v.mu.RUnlock()
+
+	return file
+}
+
+// describeFS returns a short description of the "fsys" file system.
+func describeFS(fsys fs.FS) string {
+	switch fsys.(type) {
+	case *MemoryFileSystem:
+		return "memory"
+	case embed.FS, *embed.FS:
+		return "embed"
+	case *httpFS:
+		return "http.FileSystem"
+	}
+
+	if value := reflect.ValueOf(fsys); value.Kind() == reflect.String { // e.g. os.DirFS.
+		return fmt.Sprintf("dir %q", value.String())
+	}
+
+	return fmt.Sprintf("%T", fsys)
+}
+
+// dumpData returns the indented JSON of "data",
+// or its Go representation if it can not be encoded to JSON.
+func dumpData(data any) string {
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%+v", data)
+	}
+
+	return string(b)
+}
+
+var toolbarTemplate = template.Must(template.New("toolbar").Parse(`<div id="blocks-toolbar" style="position:fixed;bottom:0;left:0;right:0;max-height:50vh;overflow:auto;z-index:2147483647;background:#222;color:#eee;font:12px/1.5 monospace;padding:.4em 1em;border-top:2px solid #c00;">
+<details>
+<summary><strong>blocks</strong> template: {{ .Template.Name }}{{ with .Layout.Name }} | layout: {{ . }}{{ end }} | partials: {{ len .Partials }} | {{ .Elapsed }} | fs: {{ .FS }}</summary>
+<table style="border-collapse:collapse;margin:.5em 0;">
+<tr><th align="left">Kind</th><th align="left">Name</th><th align="left">File</th><th align="left">Time</th><th align="left">Bytes</th></tr>
+<tr><td>template</td><td>{{ .Template.Name }}</td><td>{{ .Template.Filename }}</td><td></td><td></td></tr>
+{{- if .Layout.Name }}
+<tr><td>layout</td><td>{{ .Layout.Name }}</td><td>{{ .Layout.Filename }}</td><td></td><td></td></tr>
+{{- end }}
+{{- range .Partials }}
+<tr><td>partial</td><td>{{ .Name }}</td><td>{{ .Filename }}</td><td>{{ .Duration }}</td><td>{{ .Bytes }}{{ with .Err }} <span style="color:#f66;">{{ . }}</span>{{ end }}</td></tr>
+{{- end }}
+</table>
+<details>
+<summary>Data ({{ .DataType }})</summary>
+<pre style="white-space:pre-wrap;margin:0;">{{ .Data }}</pre>
+</details>
+</details>
+</div>`))
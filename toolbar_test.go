@@ -0,0 +1,66 @@
+package blocks_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+func TestDevToolbar(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<html><body>{{ yield . }}</body></html>`), nil)
+	mfs.ParseTemplate("index.html", []byte(`<h1>{{ .Title }}</h1>{{ partial "partials/footer" . }}`), nil)
+	mfs.ParseTemplate("partials/footer.html", []byte(`<footer>{{ .Title }}</footer>`), nil)
+
+	views := blocks.New(mfs).DevToolbar(true)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]any{"Title": "Home"}
+
+	var b strings.Builder
+	if err := views.ExecuteTemplate(&b, "index", "main", data); err != nil {
+		t.Fatal(err)
+	}
+	if page := b.String(); strings.Contains(page, "blocks-toolbar") {
+		t.Fatalf("expected no toolbar when Reload is off but got:\n%s", page)
+	}
+
+	views.Reload(true)
+
+	b.Reset()
+	if err := views.ExecuteTemplate(&b, "index", "main", data); err != nil {
+		t.Fatal(err)
+	}
+
+	page := b.String()
+	if !strings.HasPrefix(page, `<html><body><h1>Home</h1><footer>Home</footer><div id="blocks-toolbar"`) || !strings.HasSuffix(page, "</div></body></html>") {
+		t.Fatalf("expected the toolbar before the closing body tag but got:\n%s", page)
+	}
+
+	for _, expected := range []string{
+		`template: index | layout: main | partials: 1 |`,
+		` | fs: memory</summary>`,
+		`<tr><td>template</td><td>index</td><td>index.html</td><td></td>`,
+		`<tr><td>layout</td><td>main</td><td>layouts/main.html</td><td></td>`,
+		`<tr><td>partial</td><td>partials/footer</td><td>partials/footer.html</td><td>`,
+		`<summary>Data (map[string]interface {})</summary>`,
+		`&#34;Title&#34;: &#34;Home&#34;`,
+	} {
+		if !strings.Contains(page, expected) {
+			t.Fatalf("expected the toolbar to contain:\n%s\nbut got:\n%s", expected, page)
+		}
+	}
+
+	views.DevToolbar(false)
+
+	b.Reset()
+	if err := views.ExecuteTemplate(&b, "index", "main", data); err != nil {
+		t.Fatal(err)
+	}
+	if page = b.String(); page != `<html><body><h1>Home</h1><footer>Home</footer></body></html>` {
+		t.Fatalf("expected no toolbar when disabled but got:\n%s", page)
+	}
+}
@@ -0,0 +1,136 @@
+package blocks
+
+import (
+	"context"
+	"io/fs"
+	"sort"
+	"time"
+)
+
+// DefaultWatchInterval is the default interval `Watch` polls the file system.
+const DefaultWatchInterval = 500 * time.Millisecond
+
+// Watch polls the engine's file system for changes every "interval"
+// (`DefaultWatchInterval` if zero) and reloads the templates
+// when a file is added, modified or removed.
+// If the file system implements the `NotifyFS` interface
+// its native notifications are used instead of polling.
+// The optional "onReload" is called after each reload
+// with the changed file names and the load's error, if any.
+//
+// It blocks until the "ctx" is done, for development use.
+//
+// Usage:
+//
+//	go views.Watch(ctx, 0, nil)
+func (v *Blocks) Watch(ctx context.Context, interval time.Duration, onReload func(changed []string, err error)) error {
+	return watchFS(ctx, v.fs, interval, func(changed []string) {
+		err := v.LoadWithContext(ctx)
+		if onReload != nil {
+			onReload(changed, err)
+		}
+	})
+}
+
+// NotifyFS is the interface which file systems with native change notifications implement.
+// `Watch` uses it, when the engine's file system implements it, instead of polling.
+// The `MemoryFileSystem` implements it.
+type NotifyFS interface {
+	fs.FS
+	// Notify calls "onChange" with the names of the changed files
+	// and blocks until the "ctx" is done.
+	Notify(ctx context.Context, onChange func(changed []string)) error
+}
+
+// watchFS calls "onChange" with the changed file names of "fsys"
+// through its native notifications, if it is a `NotifyFS`,
+// otherwise by polling it every "interval". It blocks until the "ctx" is done.
+func watchFS(ctx context.Context, fsys fs.FS, interval time.Duration, onChange func(changed []string)) error {
+	if notifier, ok := fsys.(NotifyFS); ok {
+		return notifier.Notify(ctx, onChange)
+	}
+
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	prev, err := snapshotFS(fsys)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		next, err := snapshotFS(fsys)
+		if err != nil {
+			continue // e.g. a file removed during the walk, try again on the next tick.
+		}
+
+		changed := diffSnapshots(prev, next)
+		prev = next
+		if len(changed) > 0 {
+			onChange(changed)
+		}
+	}
+}
+
+// fileState is the state of a file on a file system snapshot.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// snapshotFS returns the state of each regular file of "fsys".
+func snapshotFS(fsys fs.FS) (map[string]fileState, error) {
+	snapshot := make(map[string]fileState)
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		snapshot[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+
+	return snapshot, err
+}
+
+// diffSnapshots returns the sorted names of the added, modified and removed files.
+func diffSnapshots(prev, next map[string]fileState) []string {
+	var changed []string
+	for path, state := range next {
+		if prevState, ok := prev[path]; !ok || prevState.size != state.size || !prevState.modTime.Equal(state.modTime) {
+			changed = append(changed, path)
+		}
+	}
+
+	for path := range prev {
+		if _, ok := next[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
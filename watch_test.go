@@ -0,0 +1,48 @@
+package blocks_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/kataras/blocks"
+)
+
+// TestReloadWhileRendering checks that the templates can be reloaded, e.g. by `Watch`,
+// while they are rendered, run with -race.
+func TestReloadWhileRendering(t *testing.T) {
+	mfs := blocks.NewMemoryFileSystem()
+	mfs.ParseTemplate("layouts/main.html", []byte(`<main>{{ yield . }}</main>`), nil)
+	mfs.ParseTemplate("index.html", []byte("---\nlayout: main\n---\n<h1>{{ . }}</h1>{{ partial \"footer\" . }}"), nil)
+	mfs.ParseTemplate("footer.html", []byte(`<footer></footer>`), nil)
+
+	views := blocks.New(mfs)
+	if err := views.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := blocks.WithLocale(context.Background(), "el")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for range 50 {
+			if err := views.Load(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 200 {
+			// The templates may be missing for a moment while they are reloaded.
+			_ = views.ExecuteTemplateContext(ctx, io.Discard, "index", "", "data")
+			_ = views.ExecuteTemplate(io.Discard, "missing", "main", nil)
+			_ = views.FrontMatter("index")
+		}
+	}()
+	wg.Wait()
+}